@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/compliance"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/config"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/connector"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/destination"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/group"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/web"
+	"go.uber.org/zap"
+)
+
+// checkCommand is the subcommand which checks the config and connectivity to the
+// Fivetran API, rather than running the exporter. It takes the same flags as the
+// exporter.
+const checkCommand = "check"
+
+type checkResult struct {
+	name string
+	err  error
+}
+
+// checkReport collects the results of the checks, so that every check is run and
+// reported even if an earlier one fails
+type checkReport struct {
+	results []*checkResult
+}
+
+func (r *checkReport) add(name string, err error) {
+	r.results = append(r.results, &checkResult{name, err})
+}
+
+func (r *checkReport) failures() int {
+	failures := 0
+	for _, result := range r.results {
+		if result.err != nil {
+			failures++
+		}
+	}
+
+	return failures
+}
+
+func (r *checkReport) print(w io.Writer) {
+	for _, result := range r.results {
+		if result.err != nil {
+			fmt.Fprintf(w, "FAIL  %s: %v\n", result.name, result.err)
+			continue
+		}
+
+		fmt.Fprintf(w, "PASS  %s\n", result.name)
+	}
+
+	if failures := r.failures(); failures != 0 {
+		fmt.Fprintf(w, "\n%d of %d checks failed\n", failures, len(r.results))
+		return
+	}
+
+	fmt.Fprintf(w, "\nall %d checks passed\n", len(r.results))
+}
+
+// check loads and validates the config, and then checks that each account can
+// authenticate against the API, resolve each of its groups, and list or describe the
+// resources of each group. The report is written to w, and true is returned if all
+// of the checks passed.
+func check(logger *zap.SugaredLogger, flagSourcer *config.FlagSourcer, w io.Writer) bool {
+	report := new(checkReport)
+	defer report.print(w)
+
+	configSourcer, err := newConfigSourcer(logger, flagSourcer)
+	if err != nil {
+		report.add("load config", err)
+		return false
+	}
+
+	cfg, err := getConfig(logger, configSourcer)
+	report.add("load config", err)
+	if err != nil {
+		return false
+	}
+
+	if cfg.complianceRulesFile != "" {
+		_, err := compliance.LoadRulesFile(logger, cfg.complianceRulesFile)
+		report.add(fmt.Sprintf("load compliance rules file %q", cfg.complianceRulesFile), err)
+	}
+
+	if cfg.webConfigFile != "" {
+		_, err := web.LoadAuthConfigFile(logger, cfg.webConfigFile)
+		report.add(fmt.Sprintf("load web config file %q", cfg.webConfigFile), err)
+	}
+
+	// The group filter is nil if it does not compile, in which case the checks which
+	// depend on it are skipped
+	groupFilter, err := group.NewRegexpFilter(logger, cfg.groupIncludeRegex, cfg.groupExcludeRegex)
+	report.add("compile group filter", err)
+
+	for _, account := range cfg.accounts {
+		checkAccount(logger, cfg, account, groupFilter, report)
+	}
+
+	return report.failures() == 0
+}
+
+func checkAccount(logger *zap.SugaredLogger,
+	cfg *exporterConfig,
+	account *config.Account,
+	groupFilter *group.RegexpFilter,
+	report *checkReport) {
+	prefix := fmt.Sprintf("account %q", account.Name)
+
+	groupLister, err := group.NewAPILister(logger, account.APIKey, account.APISecret, apiURL, cfg.apiCallTimeout)
+	if err != nil {
+		report.add(prefix+": construct group lister", err)
+		return
+	}
+
+	// Listing the groups is the cheapest authenticated call, so a failure here is most
+	// likely due to invalid credentials, and the remaining checks would fail the same way
+	_, err = groupLister.List()
+	report.add(prefix+": authenticate", err)
+	if err != nil {
+		return
+	}
+
+	groupResolver := group.NewGroupListerResolver(logger, groupLister)
+	for _, groupName := range account.CollectedGroupNames {
+		if groupFilter == nil || !groupFilter.Include(groupName) {
+			continue
+		}
+
+		groupPrefix := fmt.Sprintf("%s: group %q", prefix, groupName)
+
+		groupID, err := groupResolver.ResolveNameToID(groupName)
+		report.add(groupPrefix+": resolve", err)
+		if err != nil {
+			continue
+		}
+
+		connectorLister, err := connector.NewAPILister(logger,
+			account.APIKey,
+			account.APISecret,
+			apiURL,
+			groupID,
+			groupName,
+			cfg.apiCallTimeout)
+		if err == nil {
+			_, err = connectorLister.List()
+		}
+		report.add(groupPrefix+": list connectors", err)
+
+		// In discovery mode, the destinations of all groups are listed instead
+		if cfg.destinationDiscovery {
+			continue
+		}
+
+		destinationDescriber, err := destination.NewAPIDescriber(logger,
+			account.APIKey,
+			account.APISecret,
+			apiURL,
+			groupID,
+			groupName,
+			cfg.apiCallTimeout)
+		if err == nil {
+			_, err = destinationDescriber.Describe()
+		}
+		report.add(groupPrefix+": describe destination", err)
+	}
+
+	if cfg.destinationDiscovery && groupFilter != nil {
+		destinationLister, err := destination.NewAPILister(logger,
+			account.APIKey,
+			account.APISecret,
+			apiURL,
+			groupLister,
+			groupFilter,
+			cfg.apiCallTimeout)
+		if err == nil {
+			_, err = destinationLister.List()
+		}
+		report.add(prefix+": list destinations", err)
+	}
+}
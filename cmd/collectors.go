@@ -0,0 +1,188 @@
+package main
+
+import (
+	accountcollector "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/account"
+	agentcollector "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/agent"
+	compliancecollector "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/compliance"
+	connectorcollector "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/connector"
+	destinationcollector "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/destination"
+	externalloggingcollector "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/externallogging"
+	privatelinkcollector "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/privatelink"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/scrape"
+	transformationcollector "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/transformation"
+	usagecollector "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/usage"
+	usercollector "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/user"
+	webhookcollector "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/webhook"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/metadata"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// collectors are the collectors of a single account. They are registered with a
+// registerer which adds the account label, so that every series, including the
+// error counters, is distinct per account. Opt-in collectors are nil if disabled.
+type collectors struct {
+	connectorCollector        *connectorcollector.Collector
+	destinationCollector      *destinationcollector.Collector
+	accountCollector          *accountcollector.Collector
+	userCollector             *usercollector.Collector
+	transformationCollector   *transformationcollector.Collector
+	agentCollector            *agentcollector.Collector
+	webhookInventoryCollector *webhookcollector.InventoryCollector
+	privateLinkCollector      *privatelinkcollector.Collector
+	externalLoggingCollector  *externalloggingcollector.Collector
+	complianceCollector       *compliancecollector.Collector
+	usageCollector            *usagecollector.Collector
+	registrationCollector     *webhookcollector.RegistrationCollector
+}
+
+func newCollectors(logger *zap.SugaredLogger,
+	registerer prometheus.Registerer,
+	cfg *exporterConfig,
+	srcs *sources,
+	metadataCache *metadata.Cache) (*collectors, error) {
+	c := new(collectors)
+	var err error
+
+	// The scrape metrics are shared by the collectors of the account, so are registered
+	// once, alongside the error counters of the collectors
+	scrapeMetrics := scrape.NewMetrics(logger, registerer)
+
+	c.connectorCollector, err = connectorcollector.NewCollector(logger,
+		registerer,
+		srcs.connectorListers,
+		metadataCache,
+		scrapeMetrics,
+		readinessRefreshInterval(cfg.readinessWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	c.destinationCollector = destinationcollector.NewCollector(logger,
+		registerer,
+		srcs.destinationDescribers,
+		srcs.destinationListers,
+		metadataCache,
+		scrapeMetrics)
+
+	registerer.MustRegister(c.destinationCollector)
+	registerer.MustRegister(c.connectorCollector)
+
+	// Collection of the account info is opt-in, as it requires an extra API call per
+	// scrape for info which rarely changes
+	if cfg.collectAccount {
+		c.accountCollector = accountcollector.NewCollector(logger, registerer, srcs.accountDescriber)
+		registerer.MustRegister(c.accountCollector)
+	}
+
+	// Collection of users and teams is opt-in, as it requires account-level API permissions
+	if cfg.collectUsers {
+		c.userCollector = usercollector.NewCollector(logger,
+			registerer,
+			srcs.userLister,
+			srcs.teamLister,
+			srcs.collectedGroups)
+		registerer.MustRegister(c.userCollector)
+	}
+
+	// Collection of transformations is opt-in, as it requires an API call per dbt project
+	if cfg.collectTransformations {
+		c.transformationCollector = transformationcollector.NewCollector(logger,
+			registerer,
+			srcs.transformationListers)
+		registerer.MustRegister(c.transformationCollector)
+	}
+
+	// Collection of hybrid deployment agents is opt-in, as most accounts do not use them
+	if cfg.collectAgents {
+		c.agentCollector = agentcollector.NewCollector(logger, registerer, srcs.agentLister)
+		registerer.MustRegister(c.agentCollector)
+	}
+
+	// Collection of the inventory of supporting resources is opt-in, as it requires
+	// account-level API permissions
+	if cfg.collectInventory {
+		c.webhookInventoryCollector = webhookcollector.NewInventoryCollector(logger,
+			registerer,
+			srcs.webhookLister,
+			srcs.collectedGroups)
+		c.privateLinkCollector = privatelinkcollector.NewCollector(logger,
+			registerer,
+			srcs.privateLinkLister,
+			srcs.collectedGroups)
+		c.externalLoggingCollector = externalloggingcollector.NewCollector(logger,
+			registerer,
+			srcs.logServiceLister,
+			srcs.collectedGroups)
+		registerer.MustRegister(c.webhookInventoryCollector)
+		registerer.MustRegister(c.privateLinkCollector)
+		registerer.MustRegister(c.externalLoggingCollector)
+	}
+
+	// Compliance checking of connector schema configs is opt-in, as it requires
+	// describing the schema config of every connector to which a rule applies
+	if cfg.complianceRulesFile != "" {
+		c.complianceCollector = compliancecollector.NewCollector(logger,
+			registerer,
+			srcs.complianceChecker,
+			srcs.connectorListers)
+		registerer.MustRegister(c.complianceCollector)
+	}
+
+	// Usage collection is opt-in, as it requires an API call per connector. The usage is
+	// refreshed in the background on a slow schedule, and scrapes are served from the cache.
+	if cfg.usageRefreshInterval != 0 {
+		c.usageCollector = usagecollector.NewCollector(logger, registerer, srcs.usageListersAsListers())
+		registerer.MustRegister(c.usageCollector)
+	}
+
+	// Registration of the group webhooks delivering to the receiver is opt-in, as it
+	// requires the exporter to know its own externally-reachable URL
+	if cfg.webhookReceiverURL != "" {
+		c.registrationCollector = webhookcollector.NewRegistrationCollector(logger, srcs.webhookReconciler)
+		registerer.MustRegister(c.registrationCollector)
+	}
+
+	return c, nil
+}
+
+// setSources atomically replaces the sources of all of the collectors, e.g. when
+// the config is reloaded
+func (c *collectors) setSources(srcs *sources) {
+	c.connectorCollector.SetListers(srcs.connectorListers)
+	c.destinationCollector.SetSources(srcs.destinationDescribers, srcs.destinationListers)
+
+	if c.accountCollector != nil {
+		c.accountCollector.SetDescriber(srcs.accountDescriber)
+	}
+
+	if c.userCollector != nil {
+		c.userCollector.SetSources(srcs.userLister, srcs.teamLister, srcs.collectedGroups)
+	}
+
+	if c.transformationCollector != nil {
+		c.transformationCollector.SetListers(srcs.transformationListers)
+	}
+
+	if c.agentCollector != nil {
+		c.agentCollector.SetLister(srcs.agentLister)
+	}
+
+	if c.webhookInventoryCollector != nil {
+		c.webhookInventoryCollector.SetSources(srcs.webhookLister, srcs.collectedGroups)
+		c.privateLinkCollector.SetSources(srcs.privateLinkLister, srcs.collectedGroups)
+		c.externalLoggingCollector.SetSources(srcs.logServiceLister, srcs.collectedGroups)
+	}
+
+	if c.complianceCollector != nil {
+		c.complianceCollector.SetSources(srcs.complianceChecker, srcs.connectorListers)
+	}
+
+	if c.usageCollector != nil {
+		c.usageCollector.SetListers(srcs.usageListersAsListers())
+	}
+
+	if c.registrationCollector != nil {
+		c.registrationCollector.SetReporter(srcs.webhookReconciler)
+	}
+}
@@ -1,18 +1,34 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/blendle/zapdriver"
-	connectorcollector "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/connector"
-	destinationcollector "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/destination"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/jsonhttp"
+	buildcollector "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/build"
+	reloadcollector "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/reload"
+	webhookcollector "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/webhook"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/compliance"
 	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/config"
 	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/connector"
-	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/destination"
-	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/group"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/health"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/inventory"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/metadata"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/reload"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/status"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/version"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/web"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/webhook"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
@@ -20,6 +36,28 @@ import (
 
 const (
 	apiURL = "https://api.fivetran.com"
+
+	webhookReconcileInterval = 15 * time.Minute
+	metadataRefreshInterval  = 24 * time.Hour
+	metadataRetryInterval    = 30 * time.Second
+	metadataMaxRetryInterval = 30 * time.Minute
+	schemaCacheTTL           = 15 * time.Minute
+	secretFilePollInterval   = 30 * time.Second
+	connectorRelistInterval  = time.Minute
+	teamRefreshInterval      = 15 * time.Minute
+	vaultCallTimeout         = 10 * time.Second
+
+	// The percentage of the shutdown grace period after which in-flight API calls are
+	// cancelled
+	requestCancelGracePercent = 80
+
+	// The name of the account configured by the top-level API key and secret and
+	// collected groups, if no named accounts are configured
+	defaultAccountName = "default"
+)
+
+var (
+	configFile = flag.String("config.file", "", "Path to a YAML or JSON config file, overridden by the environment and flags")
 )
 
 func main() {
@@ -31,133 +69,681 @@ func main() {
 	logger := zapLogger.Sugar()
 	defer logger.Sync() // Flush logs at the end of the application's lifetime
 
-	// Get config from environment
-	apiKey, apiSecret, apiTimeout, collectedGroupNames, metricsPort, err := getConfig(logger)
+	// The config flags must be registered before the flags are parsed. The flags follow
+	// the subcommand, if one is given.
+	flagSourcer := config.NewFlagSourcer(logger, flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [%s] [flags]\n", os.Args[0], checkCommand)
+		flag.PrintDefaults()
+	}
+
+	args := os.Args[1:]
+	checkMode := len(args) != 0 && args[0] == checkCommand
+	if checkMode {
+		args = args[1:]
+	}
+
+	// The command-line flag set exits on error, so there is no error to handle
+	_ = flag.CommandLine.Parse(args)
+
+	if checkMode {
+		passed := check(logger, flagSourcer, os.Stdout)
+		logger.Sync()
+		if !passed {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// The exporter shuts down on SIGTERM or SIGINT, stopping the background workers
+	// derived from this context. A second signal kills the exporter immediately.
+	ctx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stopSignals()
+	go func() {
+		<-ctx.Done()
+		stopSignals()
+	}()
+
+	logger.Infow("starting exporter",
+		"version", version.Version,
+		"revision", version.Revision,
+		"goversion", version.GoVersion)
+
+	configSourcer, err := newConfigSourcer(logger, flagSourcer)
 	if err != nil {
-		logger.Fatalw("Error sourcing config", "error", err)
+		fatal(logger, "Error constructing config sourcer", "error", err)
 	}
 
-	// List the groups so that we can use the resolver to get the ID from the provided names
-	groupLister, err := group.NewAPILister(logger, apiKey, apiSecret, apiURL, 10*time.Second)
+	cfg, err := getConfig(logger, configSourcer)
 	if err != nil {
-		logger.Fatalw("Error constructing group lister", "error", err)
-	}
-	// XXX: By statically resolving the group ID to group name in main(),
-	// we will not pick up if the group name changes. However, we treat the group name as
-	// immutable even if it is technically not, as we specify the group name, not the group
-	// ID in the configuration. Using the group ID in the configuration is not as nice, as
-	// it requires knowing the non-deterministic ID in advance.
-	groupResolver := group.NewGroupListerResolver(logger, groupLister)
-
-	connectorListers := make([]connector.Lister, 0, len(collectedGroupNames))
-	destinationDescribers := make([]destination.Describer, 0, len(collectedGroupNames))
-	for _, groupName := range collectedGroupNames {
-		groupID, err := groupResolver.ResolveNameToID(groupName)
+		fatal(logger, "Error sourcing config", "error", err)
+	}
+
+	allSources, err := newAllSources(logger, cfg)
+	if err != nil {
+		fatal(logger, "Error constructing sources", "error", err)
+	}
+
+	// The background refreshes of the sources are stopped when the sources are
+	// replaced by a reload, or on shutdown
+	sourcesRunner := newBackgroundRunner(ctx)
+	sourcesRunner.runAll(allSources)
+
+	// The connector type metadata is used to give the opaque service IDs of connectors
+	// and destinations human-readable names and categories. The metadata is the same
+	// for all accounts, so it is listed using the first account.
+	metadataCache := metadata.NewCache(logger,
+		allSources[0].metadataLister,
+		metadataRefreshInterval,
+		metadataRetryInterval,
+		metadataMaxRetryInterval)
+	go metadataCache.Run(ctx)
+
+	allCollectors := make([]*collectors, 0, len(cfg.accounts))
+	for i, account := range cfg.accounts {
+		registerer := prometheus.WrapRegistererWith(prometheus.Labels{"account": account.Name},
+			prometheus.DefaultRegisterer)
+		accountCollectors, err := newCollectors(logger, registerer, cfg, allSources[i], metadataCache)
 		if err != nil {
-			logger.Fatalw("Error resolving group name to ID", "group_name", groupName, "error", err)
+			fatal(logger, "Error constructing collectors", "account", account.Name, "error", err)
 		}
+		allCollectors = append(allCollectors, accountCollectors)
+
+		// The connectors are listed in the background as well as on each scrape, so
+		// that the exporter becomes ready without first being scraped, as e.g. service
+		// discovery of Kubernetes endpoints only targets ready pods
+		go accountCollectors.connectorCollector.Run(ctx)
+	}
+
+	// The exporter's endpoints are served by a dedicated mux, rather than the default
+	// mux, so that only the endpoints registered here are exposed
+	mux := http.NewServeMux()
+
+	// The compliance report covers all accounts
+	var complianceHandler *compliance.Handler
+	if cfg.complianceRulesFile != "" {
+		complianceHandler = compliance.NewHandler(logger, complianceHandlerSources(cfg, allSources))
+		mux.Handle("/compliance", complianceHandler)
+	}
+
+	// The webhook receiver is opt-in, as it requires webhooks to be configured in Fivetran
+	// to deliver events to the exporter, signed with the shared secret. Events are
+	// delivered to a single receiver for all accounts, and the connectors of all
+	// accounts are searched to resolve the connector of an event.
+	var connectorResolver *connector.ListerResolver
+	if cfg.webhookSecret != "" {
+		connectorResolver = connector.NewListerResolver(logger,
+			resolverSources(cfg, allSources),
+			connectorRelistInterval)
+		webhookCollector := webhookcollector.NewCollector(logger, connectorResolver)
+		prometheus.MustRegister(webhookCollector)
+
+		mux.Handle("/webhook", webhook.NewReceiver(logger, cfg.webhookSecret, webhookCollector))
+	}
+
+	// The probe endpoints serve from the refresh state recorded by the connector
+	// collectors, so that probes do not trigger calls to the API
+	mux.HandleFunc("/-/healthy", health.Healthy)
+	readyHandler := health.NewReadyHandler(logger, readySources(cfg, allCollectors), cfg.readinessWindow)
+	mux.Handle("/-/ready", readyHandler)
+
+	// The status page serves from the same state as the probe endpoints
+	statusHandler := status.NewHandler(logger,
+		statusSources(cfg, allSources, allCollectors),
+		statusConfig(cfg),
+		cfg.telemetryPath)
+	mux.Handle("/", statusHandler)
 
-		// Construct a connector lister for each listed group
-		connectorLister, err := connector.NewAPILister(logger,
-			apiKey,
-			apiSecret,
-			apiURL,
-			groupID,
-			groupName,
-			apiTimeout)
+	// The inventory API serves the connectors and destinations as of the last scrape,
+	// for tools which do not read the metrics
+	inventoryHandler := inventory.NewHandler(logger, inventorySources(cfg, allSources, allCollectors))
+	mux.Handle(inventory.PathPrefix, inventoryHandler)
+
+	// Authentication of the endpoints is opt-in. The webhook receiver and reload endpoint
+	// are exempt, as they are authenticated by the webhook signature and reload token,
+	// as are the probe endpoints, as probes are typically unable to authenticate.
+	var handler http.Handler = mux
+	var authHandler *web.AuthHandler
+	if cfg.webConfigFile != "" {
+		authConfig, err := web.LoadAuthConfigFile(logger, cfg.webConfigFile)
 		if err != nil {
-			logger.Fatalw("Error constructing connector lister", "group_name", groupName, "error", err)
-		}
-		connectorListers = append(connectorListers, connectorLister)
-
-		// Construct a destination describer for each listed group
-		destinationDescriber, err := destination.NewAPIDescriber(logger,
-			apiKey,
-			apiSecret,
-			apiURL,
-			groupID,
-			groupName,
-			apiTimeout)
+			fatal(logger, "Error loading web config file", "error", err)
+		}
+
+		authHandler, err = web.NewAuthHandler(logger,
+			authConfig,
+			mux,
+			"/webhook",
+			"/-/reload",
+			"/-/healthy",
+			"/-/ready")
 		if err != nil {
-			logger.Fatalw("Error construction destination describer", "group_name", groupName, "error", err)
+			fatal(logger, "Error constructing auth handler", "error", err)
 		}
-		destinationDescribers = append(destinationDescribers, destinationDescriber)
+		handler = authHandler
 	}
 
-	connectorCollector, err := connectorcollector.NewCollector(logger, connectorListers)
-	if err != nil {
-		logger.Fatalw("Error constructing connector collector", "error", err)
+	// On reload, the sources are rebuilt from the re-read config and swapped into the
+	// running collectors. If anything fails, the running sources are left in place.
+	// Reloads are serialised by the reloader, so the running config and sources need
+	// no further locking.
+	reloader := reload.NewReloader(logger, func() error {
+		reloadedCfg, err := reloadConfig(logger, flagSourcer, cfg)
+		if err != nil {
+			return err
+		}
+
+		reloadedAllSources, err := newAllSources(logger, reloadedCfg)
+		if err != nil {
+			logger.Errorw("constructing sources", "error", err)
+			return fmt.Errorf("constructing sources: %w", err)
+		}
+
+		var reloadedAuthConfig *web.AuthConfig
+		if reloadedCfg.webConfigFile != "" {
+			reloadedAuthConfig, err = web.LoadAuthConfigFile(logger, reloadedCfg.webConfigFile)
+			if err != nil {
+				logger.Errorw("loading web config file", "error", err)
+				return fmt.Errorf("loading web config file: %w", err)
+			}
+		}
+
+		// The accounts cannot be changed without a restart, so the sources of each
+		// account replace those at the same position
+		for i, account := range reloadedCfg.accounts {
+			accountLogger := logger.With("account", account.Name)
+			logGroupChanges(accountLogger, allSources[i].collectedGroups, reloadedAllSources[i].collectedGroups)
+
+			reloadedAllSources[i].keep(accountLogger, allSources[i])
+			allCollectors[i].setSources(reloadedAllSources[i])
+		}
+
+		metadataCache.SetLister(reloadedAllSources[0].metadataLister)
+		if complianceHandler != nil {
+			complianceHandler.SetSources(complianceHandlerSources(reloadedCfg, reloadedAllSources))
+		}
+		if connectorResolver != nil {
+			connectorResolver.SetSources(resolverSources(reloadedCfg, reloadedAllSources))
+		}
+		if authHandler != nil {
+			authHandler.SetConfig(reloadedAuthConfig)
+		}
+		readyHandler.SetWindow(reloadedCfg.readinessWindow)
+		for _, accountCollectors := range allCollectors {
+			accountCollectors.connectorCollector.SetRefreshInterval(
+				readinessRefreshInterval(reloadedCfg.readinessWindow))
+		}
+		statusHandler.SetSources(statusSources(reloadedCfg, reloadedAllSources, allCollectors),
+			statusConfig(reloadedCfg))
+		inventoryHandler.SetSources(inventorySources(reloadedCfg, reloadedAllSources, allCollectors))
+
+		sourcesRunner.runAll(reloadedAllSources)
+
+		cfg, allSources = reloadedCfg, reloadedAllSources
+		return nil
+	})
+	go reloader.Run(ctx)
+
+	// Secrets read from files are only read when the config is sourced, so the files are
+	// watched to pick up rotated secrets without a SIGHUP
+	if secretFilenames := config.SecretFilenames(); len(secretFilenames) != 0 {
+		secretFileWatcher := reload.NewFileWatcher(logger, secretFilenames, secretFilePollInterval, reloader)
+		go secretFileWatcher.Run(ctx)
+	}
+	prometheus.MustRegister(reloadcollector.NewCollector(logger, reloader))
+	prometheus.MustRegister(buildcollector.NewCollector(logger))
+
+	// The reload endpoint is opt-in, as it must be authenticated with a shared token
+	if cfg.reloadToken != "" {
+		mux.Handle("/-/reload", reload.NewHandler(logger, reloader, cfg.reloadToken))
+	}
+
+	if err := run(ctx, logger, cfg, mux, handler); err != nil {
+		fatal(logger, "Error running exporter", "error", err)
+	}
+
+	logger.Infow("shut down")
+}
+
+// fatal logs the error, flushes the logs and exits. Fatalw would exit without
+// running the deferred flush of the logs.
+func fatal(logger *zap.SugaredLogger, msg string, keysAndValues ...interface{}) {
+	logger.Errorw(msg, keysAndValues...)
+	logger.Sync()
+	os.Exit(1)
+}
+
+// newAllSources constructs the sources of every account
+func newAllSources(logger *zap.SugaredLogger, cfg *exporterConfig) ([]*sources, error) {
+	allSources := make([]*sources, 0, len(cfg.accounts))
+	for _, account := range cfg.accounts {
+		srcs, err := newSources(logger, cfg, account)
+		if err != nil {
+			return nil, fmt.Errorf("constructing sources of account %q: %w", account.Name, err)
+		}
+		allSources = append(allSources, srcs)
+	}
+
+	return allSources, nil
+}
+
+func complianceHandlerSources(cfg *exporterConfig, allSources []*sources) []*compliance.HandlerSource {
+	handlerSources := make([]*compliance.HandlerSource, 0, len(allSources))
+	for i, srcs := range allSources {
+		handlerSources = append(handlerSources, &compliance.HandlerSource{
+			Account: cfg.accounts[i].Name,
+			Checker: srcs.complianceChecker,
+			Listers: srcs.connectorListers,
+		})
+	}
+
+	return handlerSources
+}
+
+// readinessRefreshInterval is the interval at which the connectors are listed in the
+// background. It is half the readiness window, so that a single failed or slow
+// listing does not on its own make the exporter unready.
+func readinessRefreshInterval(readinessWindow time.Duration) time.Duration {
+	return readinessWindow / 2
+}
+
+func readySources(cfg *exporterConfig, allCollectors []*collectors) []*health.ReadySource {
+	sources := make([]*health.ReadySource, 0, len(allCollectors))
+	for i, accountCollectors := range allCollectors {
+		sources = append(sources, &health.ReadySource{
+			Account:  cfg.accounts[i].Name,
+			Reporter: accountCollectors.connectorCollector,
+		})
+	}
+
+	return sources
+}
+
+func statusSources(cfg *exporterConfig, allSources []*sources, allCollectors []*collectors) []*status.Source {
+	statusSources := make([]*status.Source, 0, len(allSources))
+	for i, srcs := range allSources {
+		statusSources = append(statusSources, &status.Source{
+			Account:            cfg.accounts[i].Name,
+			Groups:             srcs.collectedGroups,
+			ConnectorCollector: allCollectors[i].connectorCollector,
+		})
+	}
+
+	return statusSources
+}
+
+func inventorySources(cfg *exporterConfig,
+	allSources []*sources,
+	allCollectors []*collectors) []*inventory.Source {
+	inventorySources := make([]*inventory.Source, 0, len(allSources))
+	for i, srcs := range allSources {
+		inventorySources = append(inventorySources, &inventory.Source{
+			Account:              cfg.accounts[i].Name,
+			Groups:               srcs.collectedGroups,
+			ConnectorCollector:   allCollectors[i].connectorCollector,
+			DestinationCollector: allCollectors[i].destinationCollector,
+		})
+	}
+
+	return inventorySources
+}
+
+// statusConfig returns the effective config for the status page. The page may be
+// served without authentication, so all credentials, including API keys, are redacted.
+func statusConfig(cfg *exporterConfig) []*status.Setting {
+	settings := make([]*status.Setting, 0)
+	add := func(name string, value any) {
+		settings = append(settings, &status.Setting{Name: name, Value: fmt.Sprint(value)})
+	}
+	addSecret := func(name string, value string) {
+		if value != "" {
+			value = "<redacted>"
+		}
+		add(name, value)
 	}
 
-	destinationCollector := destinationcollector.NewCollector(logger, destinationDescribers)
+	for _, account := range cfg.accounts {
+		prefix := fmt.Sprintf("account %q ", account.Name)
+		addSecret(prefix+"api_key", account.APIKey)
+		addSecret(prefix+"api_secret", account.APISecret)
+		add(prefix+"collected_group_names", strings.Join(account.CollectedGroupNames, ", "))
+	}
 
-	prometheus.MustRegister(destinationCollector)
-	prometheus.MustRegister(connectorCollector)
+	add("api_call_timeout", cfg.apiCallTimeout)
+	add("metrics_port", cfg.metricsPort)
+	add("listen_addresses", strings.Join(cfg.listenAddresses, ", "))
+	add("telemetry_path", cfg.telemetryPath)
+	add("tls_cert_file", cfg.tlsCertFile)
+	add("tls_key_file", cfg.tlsKeyFile)
+	add("web_config_file", cfg.webConfigFile)
+	add("readiness_window", cfg.readinessWindow)
+	add("shutdown_grace_period", cfg.shutdownGracePeriod)
+	add("compliance_rules_file", cfg.complianceRulesFile)
+	add("usage_refresh_interval", cfg.usageRefreshInterval)
+	addSecret("webhook_secret", cfg.webhookSecret)
+	add("webhook_receiver_url", cfg.webhookReceiverURL)
+	add("destination_discovery", cfg.destinationDiscovery)
+	add("group_include_regex", cfg.groupIncludeRegex)
+	add("group_exclude_regex", cfg.groupExcludeRegex)
+	add("collect_account", cfg.collectAccount)
+	add("collect_users", cfg.collectUsers)
+	add("collect_transformations", cfg.collectTransformations)
+	add("collect_agents", cfg.collectAgents)
+	add("collect_inventory", cfg.collectInventory)
+	addSecret("reload_token", cfg.reloadToken)
 
-	if err := run(logger, metricsPort); err != nil {
-		logger.Fatalw("Error running exporter", "error", err)
+	return settings
+}
+
+func resolverSources(cfg *exporterConfig, allSources []*sources) []*connector.ResolverSource {
+	resolverSources := make([]*connector.ResolverSource, 0, len(allSources))
+	for i, srcs := range allSources {
+		resolverSources = append(resolverSources, &connector.ResolverSource{
+			Account: cfg.accounts[i].Name,
+			Listers: srcs.connectorListers,
+		})
 	}
+
+	return resolverSources
 }
 
-func getConfig(logger *zap.SugaredLogger) (apiKey string,
-	apiSecret string,
-	apiCallTimeout time.Duration,
-	collectedGroupNames []string,
-	metricsPort uint16,
-	err error) {
-	var configSourcer config.Sourcer = config.NewEnvVarSourcer(logger)
+type exporterConfig struct {
+	accounts               []*config.Account
+	apiCallTimeout         time.Duration
+	metricsPort            uint16
+	listenAddresses        []string // Defaults to all interfaces on the metrics port
+	telemetryPath          string
+	readinessWindow        time.Duration
+	shutdownGracePeriod    time.Duration
+	tlsCertFile            string        // Optional, empty if TLS is disabled
+	tlsKeyFile             string        // Optional, empty if TLS is disabled
+	webConfigFile          string        // Optional, empty if authentication is disabled
+	complianceRulesFile    string        // Optional, empty if compliance checking is disabled
+	usageRefreshInterval   time.Duration // Optional, zero if usage collection is disabled
+	webhookSecret          string        // Optional, empty if the webhook receiver is disabled
+	webhookReceiverURL     string        // Optional, empty if webhook registration is disabled
+	destinationDiscovery   bool          // Optional, false if destinations are described per collected group
+	groupIncludeRegex      string        // Optional, empty if all groups are included. Applies to collected and discovered groups.
+	groupExcludeRegex      string        // Optional, empty if no groups are excluded. Applies to collected and discovered groups.
+	collectAccount         bool          // Optional, false if account info is not collected
+	collectUsers           bool          // Optional, false if users and teams are not collected
+	collectTransformations bool          // Optional, false if transformations are not collected
+	collectAgents          bool          // Optional, false if hybrid deployment agents are not collected
+	collectInventory       bool          // Optional, false if webhooks, private links and external logging are not collected
+	reloadToken            string        // Optional, empty if the reload endpoint is disabled
+}
+
+func getConfig(logger *zap.SugaredLogger, configSourcer config.Sourcer) (*exporterConfig, error) {
+	cfg := new(exporterConfig)
+	var err error
+
+	cfg.accounts, err = configSourcer.Accounts()
+	if err != nil && !errors.Is(err, config.ErrNotSet) {
+		logger.Errorw("getting accounts from config", "error", err)
+		return nil, fmt.Errorf("getting accounts from config: %w", err)
+	}
+
+	// Without named accounts, a single account is configured by the top-level settings
+	if len(cfg.accounts) == 0 {
+		account := &config.Account{Name: defaultAccountName}
+
+		account.APIKey, err = configSourcer.APIKey()
+		if err != nil {
+			logger.Errorw("getting API Key from config", "error", err)
+			return nil, fmt.Errorf("getting API Key from config: %w", err)
+		}
+
+		account.APISecret, err = configSourcer.APISecret()
+		if err != nil {
+			logger.Errorw("getting API Secret from config", "error", err)
+			return nil, fmt.Errorf("getting API Secret from config: %w", err)
+		}
+
+		account.CollectedGroupNames, err = configSourcer.CollectedGroupNames()
+		if err != nil {
+			logger.Errorw("getting collected group names from config", "error", err)
+			return nil, fmt.Errorf("getting collected group names from config: %w", err)
+		}
+
+		cfg.accounts = []*config.Account{account}
+	}
+
+	accountNames := make(map[string]struct{}, len(cfg.accounts))
+	for _, account := range cfg.accounts {
+		if _, ok := accountNames[account.Name]; ok {
+			logger.Errorw("duplicate account name in config", "account", account.Name)
+			return nil, fmt.Errorf("duplicate account name %q in config", account.Name)
+		}
+		accountNames[account.Name] = struct{}{}
+	}
 
-	apiKey, err = configSourcer.APIKey()
+	cfg.apiCallTimeout, err = configSourcer.APICallTimeout()
 	if err != nil {
-		logger.Errorw("getting API Key from config", "error", err)
-		err = fmt.Errorf("getting API Key from config: %w", err)
-		return
+		logger.Errorw("getting API call timeout from config", "error", err)
+		return nil, fmt.Errorf("getting API call timeout from config: %w", err)
 	}
 
-	apiSecret, err = configSourcer.APISecret()
+	cfg.metricsPort, err = configSourcer.MetricsPort()
 	if err != nil {
-		logger.Errorw("getting API Secret from config", "error", err)
-		err = fmt.Errorf("getting API Secret from config: %w", err)
-		return
+		logger.Errorw("getting metrics port from config", "error", err)
+		return nil, fmt.Errorf("getting metrics port from config: %w", err)
+	}
+
+	cfg.complianceRulesFile, err = configSourcer.ComplianceRulesFile()
+	if err != nil && !errors.Is(err, config.ErrNotSet) {
+		logger.Errorw("getting compliance rules file from config", "error", err)
+		return nil, fmt.Errorf("getting compliance rules file from config: %w", err)
 	}
 
-	apiCallTimeout, err = configSourcer.APICallTimeout()
+	cfg.usageRefreshInterval, err = configSourcer.UsageRefreshInterval()
+	if err != nil && !errors.Is(err, config.ErrNotSet) {
+		logger.Errorw("getting usage refresh interval from config", "error", err)
+		return nil, fmt.Errorf("getting usage refresh interval from config: %w", err)
+	}
+
+	cfg.webhookSecret, err = configSourcer.WebhookSecret()
+	if err != nil && !errors.Is(err, config.ErrNotSet) {
+		logger.Errorw("getting webhook secret from config", "error", err)
+		return nil, fmt.Errorf("getting webhook secret from config: %w", err)
+	}
+
+	cfg.webhookReceiverURL, err = configSourcer.WebhookReceiverURL()
+	if err != nil && !errors.Is(err, config.ErrNotSet) {
+		logger.Errorw("getting webhook receiver URL from config", "error", err)
+		return nil, fmt.Errorf("getting webhook receiver URL from config: %w", err)
+	}
+
+	if cfg.webhookReceiverURL != "" && cfg.webhookSecret == "" {
+		logger.Errorw("webhook receiver URL set without webhook secret")
+		return nil, errors.New("webhook receiver URL set without webhook secret")
+	}
+
+	cfg.destinationDiscovery, err = configSourcer.DestinationDiscovery()
+	if err != nil && !errors.Is(err, config.ErrNotSet) {
+		logger.Errorw("getting destination discovery from config", "error", err)
+		return nil, fmt.Errorf("getting destination discovery from config: %w", err)
+	}
+
+	cfg.groupIncludeRegex, err = configSourcer.GroupIncludeRegex()
+	if err != nil && !errors.Is(err, config.ErrNotSet) {
+		logger.Errorw("getting group include regex from config", "error", err)
+		return nil, fmt.Errorf("getting group include regex from config: %w", err)
+	}
+
+	cfg.groupExcludeRegex, err = configSourcer.GroupExcludeRegex()
+	if err != nil && !errors.Is(err, config.ErrNotSet) {
+		logger.Errorw("getting group exclude regex from config", "error", err)
+		return nil, fmt.Errorf("getting group exclude regex from config: %w", err)
+	}
+
+	cfg.collectAccount, err = configSourcer.CollectAccount()
+	if err != nil && !errors.Is(err, config.ErrNotSet) {
+		logger.Errorw("getting collect account from config", "error", err)
+		return nil, fmt.Errorf("getting collect account from config: %w", err)
+	}
+
+	cfg.collectUsers, err = configSourcer.CollectUsers()
+	if err != nil && !errors.Is(err, config.ErrNotSet) {
+		logger.Errorw("getting collect users from config", "error", err)
+		return nil, fmt.Errorf("getting collect users from config: %w", err)
+	}
+
+	cfg.collectTransformations, err = configSourcer.CollectTransformations()
+	if err != nil && !errors.Is(err, config.ErrNotSet) {
+		logger.Errorw("getting collect transformations from config", "error", err)
+		return nil, fmt.Errorf("getting collect transformations from config: %w", err)
+	}
+
+	cfg.collectAgents, err = configSourcer.CollectAgents()
+	if err != nil && !errors.Is(err, config.ErrNotSet) {
+		logger.Errorw("getting collect agents from config", "error", err)
+		return nil, fmt.Errorf("getting collect agents from config: %w", err)
+	}
+
+	cfg.collectInventory, err = configSourcer.CollectInventory()
+	if err != nil && !errors.Is(err, config.ErrNotSet) {
+		logger.Errorw("getting collect inventory from config", "error", err)
+		return nil, fmt.Errorf("getting collect inventory from config: %w", err)
+	}
+
+	cfg.reloadToken, err = configSourcer.ReloadToken()
+	if err != nil && !errors.Is(err, config.ErrNotSet) {
+		logger.Errorw("getting reload token from config", "error", err)
+		return nil, fmt.Errorf("getting reload token from config: %w", err)
+	}
+
+	cfg.listenAddresses, err = configSourcer.ListenAddresses()
+	if err != nil && !errors.Is(err, config.ErrNotSet) {
+		logger.Errorw("getting listen addresses from config", "error", err)
+		return nil, fmt.Errorf("getting listen addresses from config: %w", err)
+	}
+
+	if len(cfg.listenAddresses) == 0 {
+		cfg.listenAddresses = []string{fmt.Sprintf(":%d", cfg.metricsPort)}
+	}
+
+	cfg.telemetryPath, err = configSourcer.TelemetryPath()
 	if err != nil {
-		logger.Errorw("getting API call timeout from config", "error", err)
-		err = fmt.Errorf("getting API call timeout from config: %w", err)
-		return
+		logger.Errorw("getting telemetry path from config", "error", err)
+		return nil, fmt.Errorf("getting telemetry path from config: %w", err)
 	}
 
-	collectedGroupNames, err = configSourcer.CollectedGroupNames()
+	if !strings.HasPrefix(cfg.telemetryPath, "/") {
+		logger.Errorw("telemetry path not absolute", "telemetry_path", cfg.telemetryPath)
+		return nil, fmt.Errorf("telemetry path %q not absolute", cfg.telemetryPath)
+	}
+
+	cfg.tlsCertFile, err = configSourcer.TLSCertFile()
+	if err != nil && !errors.Is(err, config.ErrNotSet) {
+		logger.Errorw("getting TLS certificate file from config", "error", err)
+		return nil, fmt.Errorf("getting TLS certificate file from config: %w", err)
+	}
+
+	cfg.tlsKeyFile, err = configSourcer.TLSKeyFile()
+	if err != nil && !errors.Is(err, config.ErrNotSet) {
+		logger.Errorw("getting TLS key file from config", "error", err)
+		return nil, fmt.Errorf("getting TLS key file from config: %w", err)
+	}
+
+	if (cfg.tlsCertFile == "") != (cfg.tlsKeyFile == "") {
+		logger.Errorw("only one of TLS certificate file and TLS key file set")
+		return nil, errors.New("only one of TLS certificate file and TLS key file set")
+	}
+
+	cfg.webConfigFile, err = configSourcer.WebConfigFile()
+	if err != nil && !errors.Is(err, config.ErrNotSet) {
+		logger.Errorw("getting web config file from config", "error", err)
+		return nil, fmt.Errorf("getting web config file from config: %w", err)
+	}
+
+	cfg.readinessWindow, err = configSourcer.ReadinessWindow()
 	if err != nil {
-		logger.Errorw("getting collected group names from config", "error", err)
-		err = fmt.Errorf("getting collected group names from config: %w", err)
-		return
+		logger.Errorw("getting readiness window from config", "error", err)
+		return nil, fmt.Errorf("getting readiness window from config: %w", err)
 	}
 
-	metricsPort, err = configSourcer.MetricsPort()
+	cfg.shutdownGracePeriod, err = configSourcer.ShutdownGracePeriod()
 	if err != nil {
-		logger.Errorw("getting metrics port from config", "error", err)
-		err = fmt.Errorf("getting metrics port from config: %w", err)
-		return
+		logger.Errorw("getting shutdown grace period from config", "error", err)
+		return nil, fmt.Errorf("getting shutdown grace period from config: %w", err)
+	}
+
+	for _, account := range cfg.accounts {
+		logger.Infow("got account config",
+			"account", account.Name,
+			"api_key", account.APIKey,
+			"api_secret", "<redacted>",
+			"collected_group_names", account.CollectedGroupNames)
 	}
 
 	logger.Infow("got config",
-		"api_key", apiKey,
-		"api_secret", "<redacted>",
-		"api_call_timeout", apiCallTimeout,
-		"collected_group_names", collectedGroupNames,
-		"metrics_port", metricsPort)
-	return
+		"api_call_timeout", cfg.apiCallTimeout,
+		"metrics_port", cfg.metricsPort,
+		"listen_addresses", cfg.listenAddresses,
+		"telemetry_path", cfg.telemetryPath,
+		"tls_cert_file", cfg.tlsCertFile,
+		"tls_key_file", cfg.tlsKeyFile,
+		"web_config_file", cfg.webConfigFile,
+		"readiness_window", cfg.readinessWindow,
+		"shutdown_grace_period", cfg.shutdownGracePeriod,
+		"compliance_rules_file", cfg.complianceRulesFile,
+		"usage_refresh_interval", cfg.usageRefreshInterval,
+		"webhook_secret", "<redacted>",
+		"webhook_receiver_url", cfg.webhookReceiverURL,
+		"destination_discovery", cfg.destinationDiscovery,
+		"group_include_regex", cfg.groupIncludeRegex,
+		"group_exclude_regex", cfg.groupExcludeRegex,
+		"collect_account", cfg.collectAccount,
+		"collect_users", cfg.collectUsers,
+		"collect_transformations", cfg.collectTransformations,
+		"collect_agents", cfg.collectAgents,
+		"collect_inventory", cfg.collectInventory,
+		"reload_token", "<redacted>")
+	return cfg, nil
 }
 
-func run(logger *zap.SugaredLogger, metricsPort uint16) error {
-	http.Handle("/metrics", promhttp.Handler())
-	if err := http.ListenAndServe(fmt.Sprintf(":%d", metricsPort), nil); err != nil {
-		logger.Errorw("running webserver", "port", metricsPort, "error", err)
-		return fmt.Errorf("running webserver on port %d: %w", metricsPort, err)
+// run serves the metrics on the mux, and the mux through the handler, which may
+// wrap the mux to authenticate requests, until the context is cancelled. On
+// cancellation, in-flight requests are given the shutdown grace period to complete,
+// after which any API calls still in flight are cancelled.
+func run(ctx context.Context,
+	logger *zap.SugaredLogger,
+	cfg *exporterConfig,
+	mux *http.ServeMux,
+	handler http.Handler) error {
+	mux.Handle(cfg.telemetryPath, promhttp.Handler())
+
+	server := web.NewServer(logger, handler, cfg.listenAddresses, cfg.tlsCertFile, cfg.tlsKeyFile)
+	serveErrs := make(chan error, 1)
+	go func() {
+		serveErrs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErrs:
+		logger.Errorw("running webserver", "addresses", cfg.listenAddresses, "error", err)
+		return fmt.Errorf("running webserver: %w", err)
+	case <-ctx.Done():
+	}
+
+	logger.Infow("shutting down", "grace_period", cfg.shutdownGracePeriod)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.shutdownGracePeriod)
+	defer cancel()
+
+	// In-flight API calls are cancelled once most of the grace period has passed, so
+	// that scrapes stuck on slow calls fail, leaving their handlers time to return before
+	// the server deadline. Any remaining calls, e.g. of the background workers, are
+	// cancelled whether or not the shutdown succeeds.
+	cancelRequestsTimer := time.AfterFunc(cfg.shutdownGracePeriod*requestCancelGracePercent/100,
+		jsonhttp.CancelRequests)
+	defer cancelRequestsTimer.Stop()
+
+	err := server.Shutdown(shutdownCtx)
+	jsonhttp.CancelRequests()
+	if err != nil {
+		logger.Errorw("shutting down webserver", "addresses", cfg.listenAddresses, "error", err)
+		return fmt.Errorf("shutting down webserver: %w", err)
 	}
 
-	// Will never get here
 	return nil
 }
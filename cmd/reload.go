@@ -0,0 +1,201 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/config"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/group"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/secret"
+	"go.uber.org/zap"
+)
+
+// newConfigSourcer constructs a sourcer of the config from, in order of precedence,
+// flags, environment, Vault, config file and defaults. The config file is read on
+// construction, so a new sourcer must be constructed to pick up changes to the file.
+func newConfigSourcer(logger *zap.SugaredLogger, flagSourcer *config.FlagSourcer) (config.Sourcer, error) {
+	envVarSourcer := config.NewEnvVarSourcer(logger)
+	layers := []*config.Layer{
+		{Name: "flags", Sourcer: flagSourcer},
+		{Name: "environment", Sourcer: envVarSourcer},
+	}
+
+	vaultKVConfig, err := envVarSourcer.VaultKV()
+	if err != nil && !errors.Is(err, config.ErrNotSet) {
+		return nil, fmt.Errorf("sourcing Vault config: %w", err)
+	}
+	if vaultKVConfig != nil {
+		vaultProvider, err := secret.NewVaultKVProvider(logger,
+			vaultKVConfig.Address,
+			vaultKVConfig.Token,
+			vaultKVConfig.Mount,
+			vaultKVConfig.Path,
+			vaultCallTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("constructing Vault secret provider: %w", err)
+		}
+		layers = append(layers, &config.Layer{Name: "vault", Sourcer: config.NewSecretSourcer(vaultProvider)})
+	}
+	if *configFile != "" {
+		fileSourcer, err := config.NewFileSourcer(logger, *configFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading config file %q: %w", *configFile, err)
+		}
+		layers = append(layers, &config.Layer{Name: "config file", Sourcer: fileSourcer})
+	}
+	layers = append(layers, &config.Layer{Name: "defaults", Sourcer: config.NewDefaultSourcer()})
+
+	return config.NewLayeredSourcer(logger, layers...), nil
+}
+
+// reloadConfig re-reads the config. Settings which determine which collectors and
+// endpoints exist cannot be changed without a restart, so the running values of these
+// settings are kept, with a warning if they have changed.
+func reloadConfig(logger *zap.SugaredLogger,
+	flagSourcer *config.FlagSourcer,
+	running *exporterConfig) (*exporterConfig, error) {
+	configSourcer, err := newConfigSourcer(logger, flagSourcer)
+	if err != nil {
+		logger.Errorw("constructing config sourcer", "error", err)
+		return nil, fmt.Errorf("constructing config sourcer: %w", err)
+	}
+
+	cfg, err := getConfig(logger, configSourcer)
+	if err != nil {
+		logger.Errorw("sourcing config", "error", err)
+		return nil, fmt.Errorf("sourcing config: %w", err)
+	}
+
+	ignored := make([]string, 0)
+	// The collectors of each account are registered on startup, so accounts cannot be
+	// added, removed or renamed. The credentials and groups of an account may change.
+	if !sameAccountNames(cfg.accounts, running.accounts) {
+		ignored = append(ignored, "accounts")
+		cfg.accounts = running.accounts
+	}
+	if cfg.metricsPort != running.metricsPort {
+		ignored = append(ignored, "metrics port")
+		cfg.metricsPort = running.metricsPort
+	}
+	if !slicesEqual(cfg.listenAddresses, running.listenAddresses) {
+		ignored = append(ignored, "listen addresses")
+		cfg.listenAddresses = running.listenAddresses
+	}
+	if cfg.telemetryPath != running.telemetryPath {
+		ignored = append(ignored, "telemetry path")
+		cfg.telemetryPath = running.telemetryPath
+	}
+	if cfg.tlsCertFile != running.tlsCertFile || cfg.tlsKeyFile != running.tlsKeyFile {
+		ignored = append(ignored, "TLS certificate and key files")
+		cfg.tlsCertFile = running.tlsCertFile
+		cfg.tlsKeyFile = running.tlsKeyFile
+	}
+	if (cfg.webConfigFile == "") != (running.webConfigFile == "") {
+		ignored = append(ignored, "web config file")
+		cfg.webConfigFile = running.webConfigFile
+	}
+	if cfg.shutdownGracePeriod != running.shutdownGracePeriod {
+		ignored = append(ignored, "shutdown grace period")
+		cfg.shutdownGracePeriod = running.shutdownGracePeriod
+	}
+	if cfg.webhookSecret != running.webhookSecret {
+		ignored = append(ignored, "webhook secret")
+		cfg.webhookSecret = running.webhookSecret
+	}
+	if cfg.reloadToken != running.reloadToken {
+		ignored = append(ignored, "reload token")
+		cfg.reloadToken = running.reloadToken
+	}
+	if cfg.collectAccount != running.collectAccount {
+		ignored = append(ignored, "collect account")
+		cfg.collectAccount = running.collectAccount
+	}
+	if cfg.collectUsers != running.collectUsers {
+		ignored = append(ignored, "collect users")
+		cfg.collectUsers = running.collectUsers
+	}
+	if cfg.collectTransformations != running.collectTransformations {
+		ignored = append(ignored, "collect transformations")
+		cfg.collectTransformations = running.collectTransformations
+	}
+	if cfg.collectAgents != running.collectAgents {
+		ignored = append(ignored, "collect agents")
+		cfg.collectAgents = running.collectAgents
+	}
+	if cfg.collectInventory != running.collectInventory {
+		ignored = append(ignored, "collect inventory")
+		cfg.collectInventory = running.collectInventory
+	}
+	// The following settings may be changed, but not enabled or disabled
+	if (cfg.complianceRulesFile == "") != (running.complianceRulesFile == "") {
+		ignored = append(ignored, "compliance rules file")
+		cfg.complianceRulesFile = running.complianceRulesFile
+	}
+	if (cfg.usageRefreshInterval == 0) != (running.usageRefreshInterval == 0) {
+		ignored = append(ignored, "usage refresh interval")
+		cfg.usageRefreshInterval = running.usageRefreshInterval
+	}
+	if (cfg.webhookReceiverURL == "") != (running.webhookReceiverURL == "") {
+		ignored = append(ignored, "webhook receiver URL")
+		cfg.webhookReceiverURL = running.webhookReceiverURL
+	}
+
+	if len(ignored) != 0 {
+		logger.Warnw("ignoring changed config settings which require a restart", "settings", ignored)
+	}
+
+	return cfg, nil
+}
+
+// sameAccountNames returns whether both sets of accounts have the same names,
+// in the same order
+func sameAccountNames(accounts, otherAccounts []*config.Account) bool {
+	if len(accounts) != len(otherAccounts) {
+		return false
+	}
+
+	for i := range accounts {
+		if accounts[i].Name != otherAccounts[i].Name {
+			return false
+		}
+	}
+
+	return true
+}
+
+func slicesEqual(values, otherValues []string) bool {
+	if len(values) != len(otherValues) {
+		return false
+	}
+
+	for i := range values {
+		if values[i] != otherValues[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// logGroupChanges logs the groups added to and removed from collection by a reload
+func logGroupChanges(logger *zap.SugaredLogger, previous, reloaded []*group.Group) {
+	previousNames := make(map[string]struct{}, len(previous))
+	for _, g := range previous {
+		previousNames[g.Name] = struct{}{}
+	}
+
+	reloadedNames := make(map[string]struct{}, len(reloaded))
+	for _, g := range reloaded {
+		reloadedNames[g.Name] = struct{}{}
+
+		if _, ok := previousNames[g.Name]; !ok {
+			logger.Infow("adding collected group", "group_name", g.Name, "group_id", g.ID)
+		}
+	}
+
+	for _, g := range previous {
+		if _, ok := reloadedNames[g.Name]; !ok {
+			logger.Infow("removing collected group", "group_name", g.Name, "group_id", g.ID)
+		}
+	}
+}
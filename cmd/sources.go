@@ -0,0 +1,380 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/account"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/agent"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/compliance"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/config"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/connector"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/destination"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/externallogging"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/group"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/metadata"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/privatelink"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/schema"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/transformation"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/usage"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/user"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/webhook"
+	"go.uber.org/zap"
+)
+
+// sources are the API-backed listers and describers of an account which the
+// collectors collect from. They are built from the config on startup, and rebuilt
+// on each reload of the config so they can be swapped into the running collectors,
+// other than those refreshed in the background, which are kept where unchanged.
+// Sources of opt-in collectors are nil if the collector is disabled.
+type sources struct {
+	collectedGroups       []*group.Group
+	connectorListers      []connector.Lister
+	destinationDescribers []destination.Describer
+	destinationListers    []destination.Lister
+	metadataLister        metadata.Lister
+	accountDescriber      account.Describer
+	userLister            user.Lister
+	teamLister            *user.CachingTeamLister // Refreshed in the background
+	transformationListers []transformation.Lister
+	agentLister           agent.Lister
+	webhookLister         webhook.Lister
+	privateLinkLister     privatelink.Lister
+	logServiceLister      externallogging.Lister
+	complianceChecker     compliance.Checker
+	usageListers          []*usage.CachingLister // Refreshed in the background
+	webhookReconciler     *webhook.Reconciler    // Reconciled in the background
+}
+
+func newSources(logger *zap.SugaredLogger, cfg *exporterConfig, accountCfg *config.Account) (*sources, error) {
+	s := new(sources)
+
+	// List the groups so that we can use the resolver to get the ID from the provided names
+	groupLister, err := group.NewAPILister(logger, accountCfg.APIKey, accountCfg.APISecret, apiURL, cfg.apiCallTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("constructing group lister: %w", err)
+	}
+	// XXX: By statically resolving the group ID to group name when the sources are built,
+	// we will not pick up if the group name changes until the config is reloaded. However,
+	// we treat the group name as immutable even if it is technically not, as we specify
+	// the group name, not the group ID in the configuration. Using the group ID in the
+	// configuration is not as nice, as it requires knowing the non-deterministic ID in advance.
+	groupResolver := group.NewGroupListerResolver(logger, groupLister)
+
+	// The group filter applies both to the collected groups and to the groups whose
+	// destinations are discovered
+	groupFilter, err := group.NewRegexpFilter(logger, cfg.groupIncludeRegex, cfg.groupExcludeRegex)
+	if err != nil {
+		return nil, fmt.Errorf("constructing group filter: %w", err)
+	}
+
+	s.collectedGroups = make([]*group.Group, 0, len(accountCfg.CollectedGroupNames))
+	s.connectorListers = make([]connector.Lister, 0, len(accountCfg.CollectedGroupNames))
+	s.destinationDescribers = make([]destination.Describer, 0, len(accountCfg.CollectedGroupNames))
+	for _, groupName := range accountCfg.CollectedGroupNames {
+		if !groupFilter.Include(groupName) {
+			logger.Infow("collected group excluded by group filter", "account", accountCfg.Name, "group_name", groupName)
+			continue
+		}
+
+		groupID, err := groupResolver.ResolveNameToID(groupName)
+		if err != nil {
+			return nil, fmt.Errorf("resolving group name %q to ID: %w", groupName, err)
+		}
+		s.collectedGroups = append(s.collectedGroups, &group.Group{ID: groupID, Name: groupName})
+
+		// Construct a connector lister for each listed group
+		connectorLister, err := connector.NewAPILister(logger,
+			accountCfg.APIKey,
+			accountCfg.APISecret,
+			apiURL,
+			groupID,
+			groupName,
+			cfg.apiCallTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("constructing connector lister for group %q: %w", groupName, err)
+		}
+		s.connectorListers = append(s.connectorListers, connectorLister)
+
+		// In discovery mode, the destinations of all groups are listed instead
+		if cfg.destinationDiscovery {
+			continue
+		}
+
+		// Construct a destination describer for each listed group
+		destinationDescriber, err := destination.NewAPIDescriber(logger,
+			accountCfg.APIKey,
+			accountCfg.APISecret,
+			apiURL,
+			groupID,
+			groupName,
+			cfg.apiCallTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("constructing destination describer for group %q: %w", groupName, err)
+		}
+		s.destinationDescribers = append(s.destinationDescribers, destinationDescriber)
+	}
+
+	s.destinationListers = make([]destination.Lister, 0, 1)
+	if cfg.destinationDiscovery {
+		destinationLister, err := destination.NewAPILister(logger,
+			accountCfg.APIKey,
+			accountCfg.APISecret,
+			apiURL,
+			groupLister,
+			groupFilter,
+			cfg.apiCallTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("constructing destination lister: %w", err)
+		}
+		s.destinationListers = append(s.destinationListers, destinationLister)
+	}
+
+	s.metadataLister, err = metadata.NewAPILister(logger, accountCfg.APIKey, accountCfg.APISecret, apiURL, cfg.apiCallTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("constructing metadata lister: %w", err)
+	}
+
+	if cfg.collectAccount {
+		s.accountDescriber, err = account.NewAPIDescriber(logger, accountCfg.APIKey, accountCfg.APISecret, apiURL, cfg.apiCallTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("constructing account describer: %w", err)
+		}
+	}
+
+	if cfg.collectUsers {
+		s.userLister, err = user.NewAPILister(logger, accountCfg.APIKey, accountCfg.APISecret, apiURL, cfg.apiCallTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("constructing user lister: %w", err)
+		}
+
+		apiTeamLister, err := user.NewAPITeamLister(logger, accountCfg.APIKey, accountCfg.APISecret, apiURL, cfg.apiCallTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("constructing team lister: %w", err)
+		}
+		s.teamLister = user.NewCachingTeamLister(logger, apiTeamLister, teamRefreshInterval)
+	}
+
+	if cfg.collectTransformations {
+		s.transformationListers = make([]transformation.Lister, 0, len(s.collectedGroups))
+		for _, g := range s.collectedGroups {
+			transformationLister, err := transformation.NewAPILister(logger,
+				accountCfg.APIKey,
+				accountCfg.APISecret,
+				apiURL,
+				g.ID,
+				g.Name,
+				cfg.apiCallTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("constructing transformation lister for group %q: %w", g.Name, err)
+			}
+			s.transformationListers = append(s.transformationListers, transformationLister)
+		}
+	}
+
+	if cfg.collectAgents {
+		s.agentLister, err = agent.NewAPILister(logger,
+			accountCfg.APIKey,
+			accountCfg.APISecret,
+			apiURL,
+			groupLister,
+			cfg.apiCallTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("constructing agent lister: %w", err)
+		}
+	}
+
+	// The webhook lister is needed both for the inventory and for webhook registration
+	if cfg.collectInventory || cfg.webhookReceiverURL != "" {
+		s.webhookLister, err = webhook.NewAPILister(logger, accountCfg.APIKey, accountCfg.APISecret, apiURL, cfg.apiCallTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("constructing webhook lister: %w", err)
+		}
+	}
+
+	if cfg.collectInventory {
+		s.privateLinkLister, err = privatelink.NewAPILister(logger, accountCfg.APIKey, accountCfg.APISecret, apiURL, cfg.apiCallTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("constructing private link lister: %w", err)
+		}
+
+		s.logServiceLister, err = externallogging.NewAPILister(logger, accountCfg.APIKey, accountCfg.APISecret, apiURL, cfg.apiCallTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("constructing external logging lister: %w", err)
+		}
+	}
+
+	if cfg.complianceRulesFile != "" {
+		rules, err := compliance.LoadRulesFile(logger, cfg.complianceRulesFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading compliance rules file %q: %w", cfg.complianceRulesFile, err)
+		}
+
+		schemaDescriber, err := schema.NewAPIDescriber(logger,
+			accountCfg.APIKey,
+			accountCfg.APISecret,
+			apiURL,
+			cfg.apiCallTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("constructing schema describer: %w", err)
+		}
+
+		s.complianceChecker = compliance.NewSchemaChecker(logger,
+			rules,
+			schema.NewCachingDescriber(logger, schemaDescriber, schemaCacheTTL))
+	}
+
+	if cfg.usageRefreshInterval != 0 {
+		s.usageListers = make([]*usage.CachingLister, 0, len(s.connectorListers))
+		for _, connectorLister := range s.connectorListers {
+			apiUsageLister, err := usage.NewAPILister(logger,
+				accountCfg.APIKey,
+				accountCfg.APISecret,
+				apiURL,
+				connectorLister,
+				cfg.apiCallTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("constructing usage lister for group %q: %w", connectorLister.GetGroupName(), err)
+			}
+
+			s.usageListers = append(s.usageListers,
+				usage.NewCachingLister(logger, apiUsageLister, cfg.usageRefreshInterval))
+		}
+	}
+
+	if cfg.webhookReceiverURL != "" {
+		webhookRegistrar, err := webhook.NewAPIRegistrar(logger, accountCfg.APIKey, accountCfg.APISecret, apiURL, cfg.apiCallTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("constructing webhook registrar: %w", err)
+		}
+
+		webhookSpec := &webhook.Spec{
+			URL:    cfg.webhookReceiverURL,
+			Events: webhook.Events,
+			Secret: cfg.webhookSecret,
+		}
+		s.webhookReconciler = webhook.NewReconciler(logger,
+			s.collectedGroups,
+			webhookSpec,
+			s.webhookLister,
+			webhookRegistrar,
+			webhookReconcileInterval)
+	}
+
+	return s, nil
+}
+
+// keep replaces the reloaded sources which are refreshed in the background with those
+// of the running sources, where they are for the same groups and settings. This keeps
+// the usage and team caches, and the record of which webhook secrets have already been
+// set, across the reload. The reloaded API listers are swapped into those kept, so that
+// changed credentials (or timeouts) take effect.
+func (s *sources) keep(logger *zap.SugaredLogger, running *sources) {
+	for i, usageLister := range s.usageListers {
+		for _, runningUsageLister := range running.usageListers {
+			if runningUsageLister.GetGroupID() != usageLister.GetGroupID() ||
+				runningUsageLister.GetGroupName() != usageLister.GetGroupName() ||
+				runningUsageLister.RefreshInterval != usageLister.RefreshInterval {
+				continue
+			}
+
+			runningUsageLister.SetLister(usageLister.Lister)
+			s.usageListers[i] = runningUsageLister
+			logger.Infow("keeping usage cache", "group_name", usageLister.GetGroupName())
+			break
+		}
+	}
+
+	if s.teamLister != nil && running.teamLister != nil {
+		running.teamLister.SetTeamLister(s.teamLister.TeamLister)
+		s.teamLister = running.teamLister
+		logger.Infow("keeping team cache")
+	}
+
+	// A changed spec must be applied to every webhook, so the reconciler is only kept if
+	// the spec is unchanged
+	if s.webhookReconciler != nil &&
+		running.webhookReconciler != nil &&
+		running.webhookReconciler.Spec.Equal(s.webhookReconciler.Spec) {
+		running.webhookReconciler.SetSources(s.webhookReconciler.Groups,
+			s.webhookReconciler.Lister,
+			s.webhookReconciler.Registrar)
+		s.webhookReconciler = running.webhookReconciler
+		logger.Infow("keeping webhook reconciler")
+	}
+}
+
+// runners returns the sources which are refreshed in the background
+func (s *sources) runners() []runner {
+	runners := make([]runner, 0, len(s.usageListers)+2)
+	for _, usageLister := range s.usageListers {
+		runners = append(runners, usageLister)
+	}
+
+	if s.teamLister != nil {
+		runners = append(runners, s.teamLister)
+	}
+
+	if s.webhookReconciler != nil {
+		runners = append(runners, s.webhookReconciler)
+	}
+
+	return runners
+}
+
+type runner interface {
+	// Run runs the background refresh until the context is cancelled
+	Run(ctx context.Context)
+}
+
+// backgroundRunner runs the background refreshes of the sources. Sources kept across a
+// reload keep running, and sources which have been replaced are stopped. It is only
+// used on startup and by the reloader, which serialises reloads, so it is not locked.
+type backgroundRunner struct {
+	ctx     context.Context
+	cancels map[runner]context.CancelFunc
+}
+
+func newBackgroundRunner(ctx context.Context) *backgroundRunner {
+	return &backgroundRunner{
+		ctx:     ctx,
+		cancels: make(map[runner]context.CancelFunc),
+	}
+}
+
+// runAll runs the runners which are not yet running, and stops those running which
+// are not given
+func (b *backgroundRunner) runAll(allSources []*sources) {
+	cancels := make(map[runner]context.CancelFunc)
+	for _, srcs := range allSources {
+		for _, r := range srcs.runners() {
+			if cancel, ok := b.cancels[r]; ok {
+				cancels[r] = cancel
+				continue
+			}
+
+			ctx, cancel := context.WithCancel(b.ctx)
+			cancels[r] = cancel
+			go r.Run(ctx)
+		}
+	}
+
+	for r, cancel := range b.cancels {
+		if _, ok := cancels[r]; !ok {
+			cancel()
+		}
+	}
+
+	b.cancels = cancels
+}
+
+// usageListersAsListers returns the caching usage listers as the interface the
+// usage collector uses
+func (s *sources) usageListersAsListers() []usage.Lister {
+	usageListers := make([]usage.Lister, 0, len(s.usageListers))
+	for _, usageLister := range s.usageListers {
+		usageListers = append(usageListers, usageLister)
+	}
+
+	return usageListers
+}
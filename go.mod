@@ -6,6 +6,9 @@ require (
 	github.com/blendle/zapdriver v1.3.1
 	github.com/prometheus/client_golang v1.12.2
 	go.uber.org/zap v1.10.0
+	golang.org/x/crypto v0.0.0-20220525230936-793ad666bf5e
+	golang.org/x/time v0.0.0-20220722155302-e5dcc9cfc0b9
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
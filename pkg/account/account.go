@@ -0,0 +1,11 @@
+package account
+
+import "time"
+
+type Account struct {
+	ID        string
+	Name      string
+	Status    string     // Empty if not exposed by the API
+	Plan      string     // Empty if not exposed by the API
+	CreatedAt *time.Time // Nil if not exposed by the API
+}
@@ -0,0 +1,71 @@
+package account
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	jsonhttp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/jsonhttp"
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp/account"
+	"go.uber.org/zap"
+)
+
+type Describer interface {
+	Describe() (*Account, error)
+}
+
+// APIDescriber describes the account which the API key belongs to
+type APIDescriber struct {
+	unmarshaller *jsonhttp.JSONHTTPUnmarshaller[*apiresp.AccountInfoResp]
+	logger       *zap.SugaredLogger
+}
+
+func NewAPIDescriber(logger *zap.SugaredLogger,
+	APIKey, APISecret, APIURL string,
+	timeout time.Duration) (*APIDescriber, error) {
+	logger = getComponentLogger(logger, "api-describer")
+
+	url, err := url.Parse(APIURL + "/v1/account/info")
+	if err != nil {
+		logger.Errorw("parsing API URL", "url", APIURL, "error", err)
+		return nil, fmt.Errorf("parsing API URL %q: %w", APIURL, err)
+	}
+
+	apiToken := base64.StdEncoding.EncodeToString([]byte(APIKey + ":" + APISecret))
+	httpClient := &http.Client{
+		Timeout: timeout,
+	}
+
+	unmarshaller := jsonhttp.NewJSONHTTPUnmarshaller[*apiresp.AccountInfoResp](logger,
+		url,
+		apiToken,
+		httpClient)
+
+	return &APIDescriber{
+		unmarshaller: unmarshaller,
+		logger:       logger,
+	}, nil
+}
+
+func (d *APIDescriber) Describe() (*Account, error) {
+	accountInfoResp, err := d.unmarshaller.UnmarshallJSONFromHTTPGet()
+	if err != nil {
+		d.logger.Errorw("getting JSON HTTP response", "error", err)
+		return nil, fmt.Errorf("getting JSON HTTP response: %w", err)
+	}
+
+	data := &accountInfoResp.Data
+	account := &Account{
+		ID:        data.AccountID,
+		Name:      data.AccountName,
+		Status:    strings.ToLower(data.Status),
+		Plan:      strings.ToLower(data.Plan),
+		CreatedAt: data.CreatedAt,
+	}
+
+	d.logger.Infow("described account", "id", account.ID, "name", account.Name)
+	return account, nil
+}
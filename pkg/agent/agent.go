@@ -0,0 +1,13 @@
+package agent
+
+import "time"
+
+type Agent struct {
+	ID        string
+	Name      string
+	GroupID   string
+	GroupName string
+	Version   string
+	Up        bool
+	LastSeen  *time.Time // Nil if the agent has never connected
+}
@@ -0,0 +1,128 @@
+package agent
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	jsonhttp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/jsonhttp"
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp/agent"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/group"
+	"go.uber.org/zap"
+)
+
+type Lister interface {
+	List() ([]*Agent, error)
+}
+
+// APILister lists all hybrid deployment agents visible to the API key. Agents registered
+// as local processing agents are also returned by the hybrid deployment agents API.
+// The agents API does not return group names, so the groups are listed to resolve them.
+type APILister struct {
+	GroupLister group.Lister
+
+	logger       *zap.SugaredLogger
+	unmarshaller *jsonhttp.PaginatedJSONHTTPUnmarshaller[*apiresp.ListAgentsResp, apiresp.ListAgentsRespDataItem]
+}
+
+func NewAPILister(logger *zap.SugaredLogger,
+	APIKey, APISecret, APIURL string,
+	groupLister group.Lister,
+	timeout time.Duration) (*APILister, error) {
+	logger = getComponentLogger(logger, "api-lister")
+
+	url, err := url.Parse(APIURL + "/v1/hybrid-deployment-agents")
+	if err != nil {
+		logger.Errorw("parsing API URL", "url", APIURL, "error", err)
+		return nil, fmt.Errorf("parsing API URL %q: %w", APIURL, err)
+	}
+
+	apiToken := base64.StdEncoding.EncodeToString([]byte(APIKey + ":" + APISecret))
+	httpClient := &http.Client{
+		Timeout: timeout,
+	}
+
+	unmarshaller := jsonhttp.NewPaginatedJSONHTTPUnmarshaller[*apiresp.ListAgentsResp, apiresp.ListAgentsRespDataItem](logger,
+		url,
+		apiToken,
+		httpClient)
+
+	return &APILister{
+		GroupLister:  groupLister,
+		logger:       logger,
+		unmarshaller: unmarshaller,
+	}, nil
+}
+
+func (l *APILister) List() ([]*Agent, error) {
+	groups, err := l.GroupLister.List()
+	if err != nil {
+		l.logger.Errorw("listing groups", "error", err)
+		return nil, fmt.Errorf("listing groups: %w", err)
+	}
+
+	groupNames := make(map[string]string, len(groups)) // Keyed by group ID
+	for _, group := range groups {
+		groupNames[group.ID] = group.Name
+	}
+
+	items, err := l.unmarshaller.UnmarshallAllPagesFromHTTPGet()
+	if err != nil {
+		l.logger.Errorw("getting JSON HTTP responses", "error", err)
+		return nil, fmt.Errorf("getting JSON HTTP responses: %w", err)
+	}
+
+	agents := make([]*Agent, 0, len(items))
+	for _, item := range items {
+		groupName, ok := groupNames[item.GroupID]
+		if !ok {
+			// The group may have been created after we listed the groups
+			l.logger.Warnw("no entry for agent group ID", "id", item.ID, "group_id", item.GroupID)
+			continue
+		}
+
+		up, err := l.convertStatus(item.Status)
+		if err != nil {
+			l.logger.Errorw("converting Status",
+				"id", item.ID,
+				"group_name", groupName,
+				"status", item.Status,
+				"error", err)
+			return nil, fmt.Errorf("converting Status: %w", err)
+		}
+
+		agent := &Agent{
+			ID:        item.ID,
+			Name:      item.DisplayName,
+			GroupID:   item.GroupID,
+			GroupName: groupName,
+			Version:   item.Version,
+			Up:        up,
+			LastSeen:  item.LastSeen,
+		}
+
+		l.logger.Infow("discovered agent",
+			"id", agent.ID,
+			"name", agent.Name,
+			"group_id", agent.GroupID,
+			"group_name", agent.GroupName)
+		agents = append(agents, agent)
+	}
+
+	l.logger.Infow("listed agents from API", "count", len(agents))
+	return agents, nil
+}
+
+func (l *APILister) convertStatus(apiStatus apiresp.Status) (bool, error) {
+	switch apiStatus {
+	case apiresp.StatusOnline:
+		return true, nil
+	case apiresp.StatusOffline:
+		return false, nil
+	default:
+		l.logger.Errorw("illegal API Status", "status", apiStatus)
+		return false, fmt.Errorf("illegal API Status: %q", apiStatus)
+	}
+}
@@ -0,0 +1,12 @@
+package jsonhttp
+
+import "context"
+
+// All requests are made with this context, so that in-flight requests to the API can
+// be cancelled on shutdown without threading a context through every lister
+var _requestCtx, _cancelRequests = context.WithCancel(context.Background())
+
+// CancelRequests cancels all in-flight requests, and fails all subsequent requests
+func CancelRequests() {
+	_cancelRequests()
+}
@@ -3,6 +3,7 @@ package jsonhttp
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +13,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrUnauthorized is returned (wrapped) when the API rejects the API key and secret
+var ErrUnauthorized = errors.New("unauthorized")
+
 type GetCoder interface {
 	GetCode() apiresp.ResponseCode
 }
@@ -38,22 +42,62 @@ func NewJSONHTTPUnmarshaller[T GetCoder](logger *zap.SugaredLogger,
 }
 
 func (u JSONHTTPUnmarshaller[T]) UnmarshallJSONFromHTTPGet() (T, error) {
+	return u.unmarshallJSONFromHTTPRequest(http.MethodGet, nil)
+}
+
+// UnmarshallJSONFromHTTPPost marshalls the request body to JSON and sends it in
+// an HTTP POST request, unmarshalling the JSON response
+func (u JSONHTTPUnmarshaller[T]) UnmarshallJSONFromHTTPPost(reqBody any) (T, error) {
+	return u.unmarshallJSONFromHTTPRequest(http.MethodPost, reqBody)
+}
+
+// UnmarshallJSONFromHTTPPatch marshalls the request body to JSON and sends it in
+// an HTTP PATCH request, unmarshalling the JSON response
+func (u JSONHTTPUnmarshaller[T]) UnmarshallJSONFromHTTPPatch(reqBody any) (T, error) {
+	return u.unmarshallJSONFromHTTPRequest(http.MethodPatch, reqBody)
+}
+
+func (u JSONHTTPUnmarshaller[T]) unmarshallJSONFromHTTPRequest(method string, reqBody any) (T, error) {
 	var genericZeroValue T
 
-	httpReq := &http.Request{
+	httpReq := (&http.Request{
 		Header: make(http.Header),
-		Method: http.MethodGet,
+		Method: method,
 		URL:    u.URL,
-	}
+	}).WithContext(_requestCtx)
 	httpReq.Header.Add("Authorization", "Basic "+u.APIToken)
 
+	if reqBody != nil {
+		reqBodyBytes, err := json.Marshal(reqBody)
+		if err != nil {
+			u.logger.Errorw("marshalling HTTP request body", "url", u.URL, "method", method, "error", err)
+			return genericZeroValue, fmt.Errorf("marshalling HTTP request body: %w", err)
+		}
+
+		httpReq.Body = io.NopCloser(bytes.NewReader(reqBodyBytes))
+		httpReq.ContentLength = int64(len(reqBodyBytes))
+		httpReq.Header.Add("Content-Type", "application/json")
+	}
+
+	if err := getLimiter(u.APIToken).Wait(_requestCtx); err != nil {
+		u.logger.Errorw("waiting for rate limiter", "url", u.URL, "method", method, "error", err)
+		return genericZeroValue, fmt.Errorf("waiting for rate limiter: %w", err)
+	}
+
 	httpResp, err := u.HTTPClient.Do(httpReq)
 	if err != nil {
-		u.logger.Errorw("sending HTTP GET request", "url", u.URL, "error", err)
-		return genericZeroValue, fmt.Errorf("sending HTTP GET request: %w", err)
+		u.logger.Errorw("sending HTTP request", "url", u.URL, "method", method, "error", err)
+		return genericZeroValue, fmt.Errorf("sending HTTP %s request: %w", method, err)
+	}
+
+	if httpResp.StatusCode == http.StatusUnauthorized {
+		httpResp.Body.Close()
+		u.logger.Errorw("received unauthorized HTTP status code", "url", u.URL, "status_code", httpResp.StatusCode)
+		return genericZeroValue, fmt.Errorf("received HTTP status code %d: %w", httpResp.StatusCode, ErrUnauthorized)
 	}
 
-	if httpResp.StatusCode != http.StatusOK {
+	// Creation of resources returns 201 rather than 200
+	if httpResp.StatusCode != http.StatusOK && httpResp.StatusCode != http.StatusCreated {
 		u.logger.Errorw("received unexpected HTTP status code", "url", u.URL, "status_code", httpResp.StatusCode)
 		return genericZeroValue, fmt.Errorf("received unexpected HTTP status code %d", httpResp.StatusCode)
 	}
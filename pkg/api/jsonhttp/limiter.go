@@ -0,0 +1,33 @@
+package jsonhttp
+
+import (
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// Requests are rate limited per API token, and so per Fivetran account, so that a
+// burst of requests to one account (e.g. retries during an outage) cannot delay the
+// collection of another account, or exhaust its API rate limit
+const (
+	requestsPerSecond = 10
+	requestBurst      = 20
+)
+
+var (
+	_limitersLock = new(sync.Mutex)
+	_limiters     = make(map[string]*rate.Limiter) // Keyed by API token
+)
+
+func getLimiter(apiToken string) *rate.Limiter {
+	_limitersLock.Lock()
+	defer _limitersLock.Unlock()
+
+	limiter, ok := _limiters[apiToken]
+	if !ok {
+		limiter = rate.NewLimiter(requestsPerSecond, requestBurst)
+		_limiters[apiToken] = limiter
+	}
+
+	return limiter
+}
@@ -0,0 +1,91 @@
+package jsonhttp
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"go.uber.org/zap"
+)
+
+const (
+	pageLimit = "1000"
+
+	// maxPages bounds the number of pages fetched by a single list, in case the
+	// API never returns an empty cursor
+	maxPages = 1000
+)
+
+// Pager is implemented by the responses of list endpoints which paginate
+// their items using a cursor
+type Pager[I any] interface {
+	GetCoder
+	GetItems() []I
+	GetNextCursor() string
+}
+
+type PaginatedJSONHTTPUnmarshaller[T Pager[I], I any] struct {
+	URL        *url.URL
+	APIToken   string
+	HTTPClient *http.Client
+	logger     *zap.SugaredLogger
+}
+
+func NewPaginatedJSONHTTPUnmarshaller[T Pager[I], I any](logger *zap.SugaredLogger,
+	URL *url.URL,
+	APIToken string,
+	httpClient *http.Client) *PaginatedJSONHTTPUnmarshaller[T, I] {
+	logger = getComponentLogger(logger, "paginated-json-http-unmarshaller")
+
+	return &PaginatedJSONHTTPUnmarshaller[T, I]{
+		URL:        URL,
+		HTTPClient: httpClient,
+		APIToken:   APIToken,
+		logger:     logger,
+	}
+}
+
+// UnmarshallAllPagesFromHTTPGet follows the cursors until all pages have been
+// fetched, returning the items of every page. An error is returned if the API
+// returns a cursor it has already returned, or if there are more than maxPages pages,
+// rather than following the cursors forever.
+func (u PaginatedJSONHTTPUnmarshaller[T, I]) UnmarshallAllPagesFromHTTPGet() ([]I, error) {
+	items := make([]I, 0)
+	cursor := ""
+	seenCursors := make(map[string]struct{})
+	for page := 1; ; page++ {
+		if page > maxPages {
+			u.logger.Errorw("too many pages", "url", u.URL, "max_pages", maxPages)
+			return nil, fmt.Errorf("too many pages: more than %d", maxPages)
+		}
+
+		pageURL := *u.URL
+		query := pageURL.Query()
+		query.Set("limit", pageLimit)
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		pageURL.RawQuery = query.Encode()
+
+		unmarshaller := NewJSONHTTPUnmarshaller[T](u.logger, &pageURL, u.APIToken, u.HTTPClient)
+		resp, err := unmarshaller.UnmarshallJSONFromHTTPGet()
+		if err != nil {
+			u.logger.Errorw("getting page", "url", u.URL, "page", page, "error", err)
+			return nil, fmt.Errorf("getting page %d: %w", page, err)
+		}
+
+		items = append(items, resp.GetItems()...)
+
+		cursor = resp.GetNextCursor()
+		if cursor == "" {
+			u.logger.Infow("received all pages", "url", u.URL, "pages", page, "count", len(items))
+			return items, nil
+		}
+
+		if _, ok := seenCursors[cursor]; ok {
+			u.logger.Errorw("repeated cursor", "url", u.URL, "page", page)
+			return nil, fmt.Errorf("repeated cursor after page %d", page)
+		}
+		seenCursors[cursor] = struct{}{}
+	}
+}
@@ -0,0 +1,81 @@
+package jsonhttp_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/jsonhttp"
+	userresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp/user"
+	"go.uber.org/zap"
+)
+
+// newTestPagingServer stands in for a list endpoint, returning one item per page and
+// the next cursor chosen by nextCursor for the cursor of the request
+func newTestPagingServer(t *testing.T, nextCursor func(cursor string) string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cursor := r.URL.Query().Get("cursor")
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{
+			"code": "Success",
+			"data": {
+				"items": [{"id": "team-%s"}],
+				"next_cursor": %q
+			}
+		}`, cursor, nextCursor(cursor))
+	}))
+}
+
+func unmarshallAllTestPages(t *testing.T, serverURL string) ([]userresp.ListTeamsRespDataItem, error) {
+	t.Helper()
+
+	URL, err := url.Parse(serverURL)
+	if err != nil {
+		t.Fatalf("parsing URL: %v", err)
+	}
+
+	unmarshaller := jsonhttp.NewPaginatedJSONHTTPUnmarshaller[*userresp.ListTeamsResp](zap.NewNop().Sugar(),
+		URL,
+		"test-token",
+		http.DefaultClient)
+	return unmarshaller.UnmarshallAllPagesFromHTTPGet()
+}
+
+func TestUnmarshallAllPagesFromHTTPGet(t *testing.T) {
+	server := newTestPagingServer(t, func(cursor string) string {
+		switch cursor {
+		case "":
+			return "a"
+		case "a":
+			return "b"
+		default:
+			return ""
+		}
+	})
+	defer server.Close()
+
+	items, err := unmarshallAllTestPages(t, server.URL)
+	if err != nil {
+		t.Fatalf("getting all pages: %v", err)
+	}
+
+	if len(items) != 3 {
+		t.Errorf("got %d items, want %d", len(items), 3)
+	}
+}
+
+func TestUnmarshallAllPagesFromHTTPGetRepeatedCursor(t *testing.T) {
+	server := newTestPagingServer(t, func(cursor string) string {
+		return "a"
+	})
+	defer server.Close()
+
+	if _, err := unmarshallAllTestPages(t, server.URL); err == nil {
+		t.Error("got no error, want error")
+	}
+}
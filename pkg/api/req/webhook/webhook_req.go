@@ -0,0 +1,9 @@
+package webhook
+
+// WebhookReq is the body of requests to create or update a webhook
+type WebhookReq struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active bool     `json:"active"`
+	Secret string   `json:"secret"`
+}
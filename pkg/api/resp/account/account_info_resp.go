@@ -0,0 +1,26 @@
+package account
+
+import (
+	"time"
+
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp"
+)
+
+type AccountInfoResp struct {
+	Code apiresp.ResponseCode
+	Data AccountInfoRespData
+}
+
+func (r *AccountInfoResp) GetCode() apiresp.ResponseCode {
+	return r.Code
+}
+
+type AccountInfoRespData struct {
+	AccountID   string     `json:"account_id"`
+	AccountName string     `json:"account_name"`
+	UserID      string     `json:"user_id"`       // The user the API key belongs to
+	SystemKeyID string     `json:"system_key_id"` // Empty unless a system key is used
+	Status      string     // Not returned for all accounts
+	Plan        string     // Not returned for all accounts
+	CreatedAt   *time.Time `json:"created_at"` // Not returned for all accounts
+}
@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"time"
+
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp"
+)
+
+type ListAgentsResp struct {
+	Code apiresp.ResponseCode
+	Data ListAgentsRespData
+}
+
+func (r *ListAgentsResp) GetCode() apiresp.ResponseCode {
+	return r.Code
+}
+
+func (r *ListAgentsResp) GetItems() []ListAgentsRespDataItem {
+	return r.Data.Items
+}
+
+func (r *ListAgentsResp) GetNextCursor() string {
+	return r.Data.NextCursor
+}
+
+type ListAgentsRespData struct {
+	Items      []ListAgentsRespDataItem
+	NextCursor string `json:"next_cursor"`
+}
+
+type ListAgentsRespDataItem struct {
+	ID           string
+	DisplayName  string `json:"display_name"`
+	GroupID      string `json:"group_id"`
+	Version      string // Empty if the agent has never connected
+	Status       Status
+	RegisteredAt time.Time  `json:"registered_at"`
+	LastSeen     *time.Time `json:"last_seen"` // Null if the agent has never connected
+}
@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+/*
+The connection status of the agent. The available values are:
+ONLINE - the agent is connected to Fivetran;
+OFFLINE - the agent is not connected to Fivetran.
+*/
+type Status string
+
+const (
+	StatusOnline  Status = "ONLINE"
+	StatusOffline Status = "OFFLINE"
+)
+
+func NewStatus(str string) (Status, error) {
+	switch str {
+	case string(StatusOnline):
+		return StatusOnline, nil
+	case string(StatusOffline):
+		return StatusOffline, nil
+	default:
+		return Status(""), fmt.Errorf("illegal Status: %q", str)
+	}
+}
+
+func (s *Status) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("unmarshalling Status: %w", err)
+	}
+
+	status, err := NewStatus(str)
+	if err != nil {
+		return fmt.Errorf("constructing Status: %w", err)
+	}
+
+	*s = status
+	return nil
+}
@@ -14,8 +14,21 @@ func (r *DescribeDestinationResp) GetCode() apiresp.ResponseCode {
 }
 
 type Data struct {
-	ID          string
-	GroupID     string `json:"group_id"`
-	Service     string
-	SetupStatus SetupStatus `json:"setup_status"`
+	ID                        string
+	GroupID                   string `json:"group_id"`
+	Service                   string
+	Region                    string
+	TimeZoneOffset            string      `json:"time_zone_offset"`
+	NetworkingMethod          string      `json:"networking_method"`
+	HybridDeploymentAgentID   string      `json:"hybrid_deployment_agent_id"`
+	LocalProcessingAgentID    string      `json:"local_processing_agent_id"` // Superseded by the hybrid deployment agent
+	DaylightSavingTimeEnabled bool        `json:"daylight_saving_time_enabled"`
+	SetupStatus               SetupStatus `json:"setup_status"`
+	SetupTests                []SetupTest `json:"setup_tests"` // Only present if setup tests have been run
+}
+
+type SetupTest struct {
+	Title   string
+	Status  SetupTestStatus
+	Message string
 }
@@ -0,0 +1,27 @@
+package destination
+
+import (
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp"
+)
+
+type ListDestinationsResp struct {
+	Code apiresp.ResponseCode
+	Data ListDestinationsRespData
+}
+
+func (r *ListDestinationsResp) GetCode() apiresp.ResponseCode {
+	return r.Code
+}
+
+func (r *ListDestinationsResp) GetItems() []Data {
+	return r.Data.Items
+}
+
+func (r *ListDestinationsResp) GetNextCursor() string {
+	return r.Data.NextCursor
+}
+
+type ListDestinationsRespData struct {
+	Items      []Data
+	NextCursor string `json:"next_cursor"`
+}
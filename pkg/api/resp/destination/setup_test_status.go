@@ -0,0 +1,50 @@
+package destination
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type SetupTestStatus string
+
+const (
+	SetupTestStatusPassed    SetupTestStatus = "PASSED"
+	SetupTestStatusWarning   SetupTestStatus = "WARNING"
+	SetupTestStatusSkipped   SetupTestStatus = "SKIPPED"
+	SetupTestStatusFailed    SetupTestStatus = "FAILED"
+	SetupTestStatusJobFailed SetupTestStatus = "JOB_FAILED"
+)
+
+func NewSetupTestStatus(str string) (SetupTestStatus, error) {
+	switch str {
+	case string(SetupTestStatusPassed):
+		return SetupTestStatusPassed, nil
+	case string(SetupTestStatusWarning):
+		return SetupTestStatusWarning, nil
+	case string(SetupTestStatusSkipped):
+		return SetupTestStatusSkipped, nil
+	case string(SetupTestStatusFailed):
+		return SetupTestStatusFailed, nil
+	case string(SetupTestStatusJobFailed):
+		return SetupTestStatusJobFailed, nil
+	default:
+		return SetupTestStatus(""), fmt.Errorf("illegal SetupTestStatus: %q", str)
+	}
+}
+
+func (sts *SetupTestStatus) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+
+	// Fivetran may add setup test statuses at any time, so an unrecognised status is
+	// kept verbatim rather than failing the decoding of the whole destination
+	setupTestStatus, err := NewSetupTestStatus(str)
+	if err != nil {
+		setupTestStatus = SetupTestStatus(str)
+	}
+
+	*sts = setupTestStatus
+	return nil
+}
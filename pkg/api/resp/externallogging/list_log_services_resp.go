@@ -0,0 +1,34 @@
+package externallogging
+
+import (
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp"
+)
+
+type ListLogServicesResp struct {
+	Code apiresp.ResponseCode
+	Data ListLogServicesRespData
+}
+
+func (r *ListLogServicesResp) GetCode() apiresp.ResponseCode {
+	return r.Code
+}
+
+func (r *ListLogServicesResp) GetItems() []ListLogServicesRespDataItem {
+	return r.Data.Items
+}
+
+func (r *ListLogServicesResp) GetNextCursor() string {
+	return r.Data.NextCursor
+}
+
+type ListLogServicesRespData struct {
+	Items      []ListLogServicesRespDataItem
+	NextCursor string `json:"next_cursor"`
+}
+
+type ListLogServicesRespDataItem struct {
+	ID      string // The same as the ID of the group the logs are from
+	GroupID string `json:"group_id"`
+	Service string
+	Enabled bool
+}
@@ -0,0 +1,34 @@
+package metadata
+
+import (
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp"
+)
+
+type ListConnectorTypesResp struct {
+	Code apiresp.ResponseCode
+	Data ListConnectorTypesRespData
+}
+
+func (r *ListConnectorTypesResp) GetCode() apiresp.ResponseCode {
+	return r.Code
+}
+
+func (r *ListConnectorTypesResp) GetItems() []ListConnectorTypesRespDataItem {
+	return r.Data.Items
+}
+
+func (r *ListConnectorTypesResp) GetNextCursor() string {
+	return r.Data.NextCursor
+}
+
+type ListConnectorTypesRespData struct {
+	Items      []ListConnectorTypesRespDataItem
+	NextCursor string `json:"next_cursor"`
+}
+
+type ListConnectorTypesRespDataItem struct {
+	ID          string // The service, e.g. google_analytics_4
+	Name        string // The display name, e.g. Google Analytics 4
+	Type        string // The category, e.g. Marketing
+	Description string
+}
@@ -0,0 +1,38 @@
+package privatelink
+
+import (
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp"
+)
+
+type ListPrivateLinksResp struct {
+	Code apiresp.ResponseCode
+	Data ListPrivateLinksRespData
+}
+
+func (r *ListPrivateLinksResp) GetCode() apiresp.ResponseCode {
+	return r.Code
+}
+
+func (r *ListPrivateLinksResp) GetItems() []ListPrivateLinksRespDataItem {
+	return r.Data.Items
+}
+
+func (r *ListPrivateLinksResp) GetNextCursor() string {
+	return r.Data.NextCursor
+}
+
+type ListPrivateLinksRespData struct {
+	Items      []ListPrivateLinksRespDataItem
+	NextCursor string `json:"next_cursor"`
+}
+
+type ListPrivateLinksRespDataItem struct {
+	ID            string
+	Name          string
+	GroupID       string `json:"group_id"`
+	Service       string
+	Region        string
+	CloudProvider string `json:"cloud_provider"`
+	State         State
+	StateSummary  string `json:"state_summary"` // Explanation of the state, e.g. the reason for failure
+}
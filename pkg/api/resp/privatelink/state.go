@@ -0,0 +1,56 @@
+package privatelink
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+/*
+The provisioning state of the private link. The available values are:
+PENDING - the private link has been requested but not yet provisioned;
+CREATING - the private link is being provisioned;
+READY - the private link is provisioned and usable;
+FAILED - provisioning of the private link failed;
+DELETING - the private link is being deleted.
+*/
+type State string
+
+const (
+	StatePending  State = "PENDING"
+	StateCreating State = "CREATING"
+	StateReady    State = "READY"
+	StateFailed   State = "FAILED"
+	StateDeleting State = "DELETING"
+)
+
+func NewState(str string) (State, error) {
+	switch str {
+	case string(StatePending):
+		return StatePending, nil
+	case string(StateCreating):
+		return StateCreating, nil
+	case string(StateReady):
+		return StateReady, nil
+	case string(StateFailed):
+		return StateFailed, nil
+	case string(StateDeleting):
+		return StateDeleting, nil
+	default:
+		return State(""), fmt.Errorf("illegal State: %q", str)
+	}
+}
+
+func (s *State) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("unmarshalling State: %w", err)
+	}
+
+	state, err := NewState(str)
+	if err != nil {
+		return fmt.Errorf("constructing State: %w", err)
+	}
+
+	*s = state
+	return nil
+}
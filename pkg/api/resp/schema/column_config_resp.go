@@ -0,0 +1,20 @@
+package schema
+
+import (
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp"
+)
+
+// ColumnConfigResp is the config of all columns of a single table, including those
+// which have not been explicitly configured
+type ColumnConfigResp struct {
+	Code apiresp.ResponseCode
+	Data ColumnConfigRespData
+}
+
+func (r *ColumnConfigResp) GetCode() apiresp.ResponseCode {
+	return r.Code
+}
+
+type ColumnConfigRespData struct {
+	Columns map[string]Column
+}
@@ -0,0 +1,39 @@
+package schema
+
+import (
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp"
+)
+
+type SchemaConfigResp struct {
+	Code apiresp.ResponseCode
+	Data SchemaConfigRespData
+}
+
+func (r *SchemaConfigResp) GetCode() apiresp.ResponseCode {
+	return r.Code
+}
+
+type SchemaConfigRespData struct {
+	SchemaChangeHandling string `json:"schema_change_handling"`
+	Schemas              map[string]Schema
+}
+
+type Schema struct {
+	NameInDestination string `json:"name_in_destination"`
+	Enabled           bool
+	Tables            map[string]Table
+}
+
+type Table struct {
+	NameInDestination string `json:"name_in_destination"`
+	Enabled           bool
+	// NOTE: Only columns which have been explicitly configured are returned by the API.
+	// All columns are returned by the column config endpoint of the table.
+	Columns map[string]Column
+}
+
+type Column struct {
+	NameInDestination string `json:"name_in_destination"`
+	Enabled           bool
+	Hashed            bool
+}
@@ -0,0 +1,32 @@
+package transformation
+
+import (
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp"
+)
+
+type ListProjectsResp struct {
+	Code apiresp.ResponseCode
+	Data ListProjectsRespData
+}
+
+func (r *ListProjectsResp) GetCode() apiresp.ResponseCode {
+	return r.Code
+}
+
+func (r *ListProjectsResp) GetItems() []ListProjectsRespDataItem {
+	return r.Data.Items
+}
+
+func (r *ListProjectsResp) GetNextCursor() string {
+	return r.Data.NextCursor
+}
+
+type ListProjectsRespData struct {
+	Items      []ListProjectsRespDataItem
+	NextCursor string `json:"next_cursor"`
+}
+
+type ListProjectsRespDataItem struct {
+	ID      string
+	GroupID string `json:"group_id"`
+}
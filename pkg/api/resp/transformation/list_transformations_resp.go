@@ -0,0 +1,47 @@
+package transformation
+
+import (
+	"time"
+
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp"
+)
+
+type ListTransformationsResp struct {
+	Code apiresp.ResponseCode
+	Data ListTransformationsRespData
+}
+
+func (r *ListTransformationsResp) GetCode() apiresp.ResponseCode {
+	return r.Code
+}
+
+func (r *ListTransformationsResp) GetItems() []ListTransformationsRespDataItem {
+	return r.Data.Items
+}
+
+func (r *ListTransformationsResp) GetNextCursor() string {
+	return r.Data.NextCursor
+}
+
+type ListTransformationsRespData struct {
+	Items      []ListTransformationsRespDataItem
+	NextCursor string `json:"next_cursor"`
+}
+
+type ListTransformationsRespDataItem struct {
+	ID              string
+	DBTProjectID    string `json:"dbt_project_id"`
+	OutputModelName string `json:"output_model_name"`
+	Status          Status
+	Paused          bool
+	LastRun         *time.Time `json:"last_run"` // Null if the transformation has never run
+	NextRun         *time.Time `json:"next_run"` // Null if the transformation is not scheduled
+	Schedule        Schedule
+}
+
+type Schedule struct {
+	ScheduleType string   `json:"schedule_type"` // e.g. INTEGRATED, INTERVAL, TIME_OF_DAY
+	IntervalMins int      `json:"interval"`      // Only set for INTERVAL schedules
+	TimeOfDay    string   `json:"time_of_day"`   // Only set for TIME_OF_DAY schedules
+	DaysOfWeek   []string `json:"days_of_week"`
+}
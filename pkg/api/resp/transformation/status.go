@@ -0,0 +1,52 @@
+package transformation
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+/*
+The status of the last run of the transformation. The available values are:
+SUCCEEDED - the last run succeeded;
+FAILED - the last run failed;
+RUNNING - the transformation is currently running;
+PENDING - the transformation has not yet run.
+*/
+type Status string
+
+const (
+	StatusSucceeded Status = "SUCCEEDED"
+	StatusFailed    Status = "FAILED"
+	StatusRunning   Status = "RUNNING"
+	StatusPending   Status = "PENDING"
+)
+
+func NewStatus(str string) (Status, error) {
+	switch str {
+	case string(StatusSucceeded):
+		return StatusSucceeded, nil
+	case string(StatusFailed):
+		return StatusFailed, nil
+	case string(StatusRunning):
+		return StatusRunning, nil
+	case string(StatusPending):
+		return StatusPending, nil
+	default:
+		return Status(""), fmt.Errorf("illegal Status: %q", str)
+	}
+}
+
+func (s *Status) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("unmarshalling Status: %w", err)
+	}
+
+	status, err := NewStatus(str)
+	if err != nil {
+		return fmt.Errorf("constructing Status: %w", err)
+	}
+
+	*s = status
+	return nil
+}
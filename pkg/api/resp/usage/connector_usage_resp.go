@@ -0,0 +1,23 @@
+package usage
+
+import (
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp"
+)
+
+type ConnectorUsageResp struct {
+	Code apiresp.ResponseCode
+	Data ConnectorUsageRespData
+}
+
+func (r *ConnectorUsageResp) GetCode() apiresp.ResponseCode {
+	return r.Code
+}
+
+type ConnectorUsageRespData struct {
+	Items []ConnectorUsageRespDataItem
+}
+
+type ConnectorUsageRespDataItem struct {
+	Month             string // Formatted as YYYY-MM
+	MonthlyActiveRows int64  `json:"monthly_active_rows"`
+}
@@ -0,0 +1,32 @@
+package user
+
+import (
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp"
+)
+
+type ListTeamMembershipsResp struct {
+	Code apiresp.ResponseCode
+	Data ListTeamMembershipsRespData
+}
+
+func (r *ListTeamMembershipsResp) GetCode() apiresp.ResponseCode {
+	return r.Code
+}
+
+func (r *ListTeamMembershipsResp) GetItems() []ListTeamMembershipsRespDataItem {
+	return r.Data.Items
+}
+
+func (r *ListTeamMembershipsResp) GetNextCursor() string {
+	return r.Data.NextCursor
+}
+
+type ListTeamMembershipsRespData struct {
+	Items      []ListTeamMembershipsRespDataItem
+	NextCursor string `json:"next_cursor"`
+}
+
+type ListTeamMembershipsRespDataItem struct {
+	ID   string // The ID of the group (or connector) the team is a member of
+	Role string
+}
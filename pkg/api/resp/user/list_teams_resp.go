@@ -0,0 +1,33 @@
+package user
+
+import (
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp"
+)
+
+type ListTeamsResp struct {
+	Code apiresp.ResponseCode
+	Data ListTeamsRespData
+}
+
+func (r *ListTeamsResp) GetCode() apiresp.ResponseCode {
+	return r.Code
+}
+
+func (r *ListTeamsResp) GetItems() []ListTeamsRespDataItem {
+	return r.Data.Items
+}
+
+func (r *ListTeamsResp) GetNextCursor() string {
+	return r.Data.NextCursor
+}
+
+type ListTeamsRespData struct {
+	Items      []ListTeamsRespDataItem
+	NextCursor string `json:"next_cursor"`
+}
+
+type ListTeamsRespDataItem struct {
+	ID   string
+	Name string
+	Role string
+}
@@ -0,0 +1,39 @@
+package user
+
+import (
+	"time"
+
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp"
+)
+
+type ListUsersResp struct {
+	Code apiresp.ResponseCode
+	Data ListUsersRespData
+}
+
+func (r *ListUsersResp) GetCode() apiresp.ResponseCode {
+	return r.Code
+}
+
+func (r *ListUsersResp) GetItems() []ListUsersRespDataItem {
+	return r.Data.Items
+}
+
+func (r *ListUsersResp) GetNextCursor() string {
+	return r.Data.NextCursor
+}
+
+type ListUsersRespData struct {
+	Items      []ListUsersRespDataItem
+	NextCursor string `json:"next_cursor"`
+}
+
+type ListUsersRespDataItem struct {
+	ID         string
+	Email      string
+	Role       string
+	Verified   bool
+	Invited    bool
+	Active     bool
+	LoggedInAt *time.Time `json:"logged_in_at"` // Null if the user has never logged in
+}
@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp"
+)
+
+type ListWebhooksResp struct {
+	Code apiresp.ResponseCode
+	Data ListWebhooksRespData
+}
+
+func (r *ListWebhooksResp) GetCode() apiresp.ResponseCode {
+	return r.Code
+}
+
+func (r *ListWebhooksResp) GetItems() []Webhook {
+	return r.Data.Items
+}
+
+func (r *ListWebhooksResp) GetNextCursor() string {
+	return r.Data.NextCursor
+}
+
+type ListWebhooksRespData struct {
+	Items      []Webhook
+	NextCursor string `json:"next_cursor"`
+}
+
+type Webhook struct {
+	ID      string
+	Type    string // "account" or "group"
+	GroupID string `json:"group_id"`
+	URL     string
+	Events  []string
+	Active  bool
+}
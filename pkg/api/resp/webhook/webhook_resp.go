@@ -0,0 +1,14 @@
+package webhook
+
+import (
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp"
+)
+
+type WebhookResp struct {
+	Code apiresp.ResponseCode
+	Data Webhook
+}
+
+func (r *WebhookResp) GetCode() apiresp.ResponseCode {
+	return r.Code
+}
@@ -0,0 +1,117 @@
+package account
+
+import (
+	"sync"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/account"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	namespace = "fivetran"
+	subsystem = "account"
+
+	gaugeInfoName          = "info"
+	gaugeCreatedName       = "created_timestamp_seconds"
+	counterErrorsTotalName = "errors_total"
+)
+
+var (
+	gaugeInfoFQName = prometheus.BuildFQName(namespace, subsystem, gaugeInfoName)
+	gaugeInfoDesc   = prometheus.NewDesc(
+		gaugeInfoFQName,
+		infoEnumGauge.Describe(),
+		[]string{"account_id", "name", "status", "plan"},
+		prometheus.Labels{})
+	gaugeCreatedFQName = prometheus.BuildFQName(namespace, subsystem, gaugeCreatedName)
+	gaugeCreatedDesc   = prometheus.NewDesc(
+		gaugeCreatedFQName,
+		"Time the account was created, in seconds since the epoch",
+		[]string{"account_id"},
+		prometheus.Labels{})
+)
+
+type Collector struct {
+	Describer account.Describer
+
+	lock               *sync.RWMutex
+	counterErrorsTotal prometheus.Counter
+	logger             *zap.SugaredLogger
+}
+
+func NewCollector(logger *zap.SugaredLogger, registerer prometheus.Registerer, describer account.Describer) *Collector {
+	logger = getComponentLogger(logger, "collector")
+
+	counterErrorsTotal := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      counterErrorsTotalName,
+		Help:      "Total errors encountered describing the account",
+	})
+	registerer.MustRegister(counterErrorsTotal)
+
+	return &Collector{
+		Describer:          describer,
+		lock:               new(sync.RWMutex),
+		counterErrorsTotal: counterErrorsTotal,
+		logger:             logger,
+	}
+}
+
+func (c *Collector) Describe(descsChan chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, descsChan)
+}
+
+// SetDescriber atomically replaces the describer, e.g. when the config is reloaded
+func (c *Collector) SetDescriber(describer account.Describer) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.Describer = describer
+}
+
+func (c *Collector) Collect(metricsChan chan<- prometheus.Metric) {
+	c.lock.RLock()
+	describer := c.Describer
+	c.lock.RUnlock()
+
+	account, err := describer.Describe()
+	if err != nil {
+		// We do not have to send this metric on the metricsChan as it is already registered
+		// (it is a metric _belonging_ to this collector, rather than _collected_)
+		c.counterErrorsTotal.Inc()
+		c.logger.Errorw("describing account", "error", err)
+		return
+	}
+
+	metricsChan <- prometheus.MustNewConstMetric(gaugeInfoDesc,
+		prometheus.GaugeValue,
+		metrics.EnumGaugeValuePresent.GaugeValue(),
+		account.ID,     // `account_id` label
+		account.Name,   // `name` label
+		account.Status, // `status` label
+		account.Plan)   // `plan` label
+
+	c.logger.Infow("collected metric",
+		"account_id", account.ID,
+		"name", account.Name,
+		"status", account.Status,
+		"plan", account.Plan,
+		"metric", gaugeInfoFQName)
+
+	// The creation time is not exposed for all accounts
+	if account.CreatedAt == nil {
+		return
+	}
+
+	metricsChan <- prometheus.MustNewConstMetric(gaugeCreatedDesc,
+		prometheus.GaugeValue,
+		float64(account.CreatedAt.Unix()),
+		account.ID) // `account_id` label
+
+	c.logger.Infow("collected metric",
+		"account_id", account.ID,
+		"metric", gaugeCreatedFQName)
+}
@@ -0,0 +1,8 @@
+package account
+
+import "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+
+var (
+	infoEnumGauge = metrics.NewEnumGauge(metrics.PresentMetricsGaugeValues,
+		"Information about the account the API key belongs to")
+)
@@ -0,0 +1,191 @@
+package agent
+
+import (
+	"sync"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/agent"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+type collectFunc func([]*agent.Agent, chan<- prometheus.Metric, *sync.WaitGroup)
+
+const (
+	namespace = "fivetran"
+	subsystem = "agent"
+
+	gaugeUpName            = "up"
+	gaugeLastSeenName      = "last_seen_timestamp_seconds"
+	gaugeInfoName          = "info"
+	counterErrorsTotalName = "errors_total"
+)
+
+// The agent_id label matches that of fivetran_destination_info, so destinations can
+// be joined onto the health of the agent which runs them.
+var (
+	gaugeUpFQName = prometheus.BuildFQName(namespace, subsystem, gaugeUpName)
+	gaugeUpDesc   = prometheus.NewDesc(
+		gaugeUpFQName,
+		upEnumGauge.Describe(),
+		[]string{"agent_id", "group_name"},
+		prometheus.Labels{})
+	gaugeLastSeenFQName = prometheus.BuildFQName(namespace, subsystem, gaugeLastSeenName)
+	gaugeLastSeenDesc   = prometheus.NewDesc(
+		gaugeLastSeenFQName,
+		"Time an agent was last seen by Fivetran, in seconds since the epoch",
+		[]string{"agent_id", "group_name"},
+		prometheus.Labels{})
+	gaugeInfoFQName = prometheus.BuildFQName(namespace, subsystem, gaugeInfoName)
+	gaugeInfoDesc   = prometheus.NewDesc(
+		gaugeInfoFQName,
+		infoEnumGauge.Describe(),
+		[]string{"agent_id", "group_name", "group_id", "name", "version"},
+		prometheus.Labels{})
+)
+
+type Collector struct {
+	Lister agent.Lister
+
+	lock               *sync.RWMutex
+	counterErrorsTotal prometheus.Counter
+	collectFuncs       []collectFunc
+	logger             *zap.SugaredLogger
+}
+
+func NewCollector(logger *zap.SugaredLogger, registerer prometheus.Registerer, lister agent.Lister) *Collector {
+	logger = getComponentLogger(logger, "collector")
+
+	counterErrorsTotal := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      counterErrorsTotalName,
+		Help:      "Total errors encountered listing agents",
+	})
+	registerer.MustRegister(counterErrorsTotal)
+
+	collector := &Collector{
+		Lister:             lister,
+		lock:               new(sync.RWMutex),
+		counterErrorsTotal: counterErrorsTotal,
+		logger:             logger,
+	}
+
+	collectFuncs := []collectFunc{
+		collector.collectUp,
+		collector.collectLastSeen,
+		collector.collectInfo,
+	}
+	collector.collectFuncs = collectFuncs
+
+	return collector
+}
+
+func (c *Collector) Describe(descsChan chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, descsChan)
+}
+
+// SetLister atomically replaces the lister, e.g. when the config is reloaded
+func (c *Collector) SetLister(lister agent.Lister) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.Lister = lister
+}
+
+func (c *Collector) Collect(metricsChan chan<- prometheus.Metric) {
+	c.lock.RLock()
+	lister := c.Lister
+	c.lock.RUnlock()
+
+	agents, err := lister.List()
+	if err != nil {
+		// We do not have to send this metric on the metricsChan as it is already registered
+		// (it is a metric _belonging_ to this collector, rather than _collected_)
+		c.counterErrorsTotal.Inc()
+		c.logger.Errorw("listing agents", "error", err)
+		return
+	}
+
+	waitGroup := new(sync.WaitGroup)
+	waitGroup.Add(len(c.collectFuncs))
+	for _, collectFunc := range c.collectFuncs {
+		go collectFunc(agents, metricsChan, waitGroup)
+	}
+	waitGroup.Wait()
+}
+
+func (c *Collector) collectUp(agents []*agent.Agent,
+	metricsChan chan<- prometheus.Metric,
+	waitGroup *sync.WaitGroup) {
+	defer waitGroup.Done()
+
+	// Create one gauge metric per agent
+	for _, a := range agents {
+		value := metrics.EnumGaugeValueFalse
+		if a.Up {
+			value = metrics.EnumGaugeValueTrue
+		}
+
+		metricsChan <- prometheus.MustNewConstMetric(gaugeUpDesc,
+			prometheus.GaugeValue,
+			value.GaugeValue(),
+			a.ID,        // `agent_id` label
+			a.GroupName) // `group_name` label
+
+		c.logger.Infow("collected metric",
+			"agent_id", a.ID,
+			"group_name", a.GroupName,
+			"metric", gaugeUpFQName)
+	}
+}
+
+func (c *Collector) collectLastSeen(agents []*agent.Agent,
+	metricsChan chan<- prometheus.Metric,
+	waitGroup *sync.WaitGroup) {
+	defer waitGroup.Done()
+
+	// Create one gauge metric per agent which has connected
+	for _, a := range agents {
+		if a.LastSeen == nil {
+			continue
+		}
+
+		metricsChan <- prometheus.MustNewConstMetric(gaugeLastSeenDesc,
+			prometheus.GaugeValue,
+			float64(a.LastSeen.Unix()),
+			a.ID,        // `agent_id` label
+			a.GroupName) // `group_name` label
+
+		c.logger.Infow("collected metric",
+			"agent_id", a.ID,
+			"group_name", a.GroupName,
+			"metric", gaugeLastSeenFQName)
+	}
+}
+
+func (c *Collector) collectInfo(agents []*agent.Agent,
+	metricsChan chan<- prometheus.Metric,
+	waitGroup *sync.WaitGroup) {
+	defer waitGroup.Done()
+
+	// Create one gauge metric per agent
+	for _, a := range agents {
+		metricsChan <- prometheus.MustNewConstMetric(gaugeInfoDesc,
+			prometheus.GaugeValue,
+			metrics.EnumGaugeValuePresent.GaugeValue(),
+			a.ID,        // `agent_id` label
+			a.GroupName, // `group_name` label
+			a.GroupID,   // `group_id` label
+			a.Name,      // `name` label
+			a.Version)   // `version` label
+
+		c.logger.Infow("collected metric",
+			"agent_id", a.ID,
+			"group_name", a.GroupName,
+			"group_id", a.GroupID,
+			"name", a.Name,
+			"version", a.Version,
+			"metric", gaugeInfoFQName)
+	}
+}
@@ -0,0 +1,8 @@
+package agent
+
+import "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+
+var (
+	infoEnumGauge = metrics.NewEnumGauge(metrics.PresentMetricsGaugeValues,
+		"Information about a hybrid deployment agent")
+)
@@ -0,0 +1,8 @@
+package agent
+
+import "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+
+var (
+	upEnumGauge = metrics.NewEnumGauge(metrics.BooleanMetricsGaugeValues,
+		"Whether an agent is connected to Fivetran")
+)
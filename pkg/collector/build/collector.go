@@ -0,0 +1,56 @@
+package build
+
+import (
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/version"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	namespace = "fivetran"
+	subsystem = "exporter"
+
+	gaugeInfoName = "build_info"
+)
+
+var (
+	gaugeInfoFQName = prometheus.BuildFQName(namespace, subsystem, gaugeInfoName)
+	gaugeInfoDesc   = prometheus.NewDesc(
+		gaugeInfoFQName,
+		infoEnumGauge.Describe(),
+		[]string{"version", "revision", "goversion"},
+		prometheus.Labels{})
+)
+
+// Collector reports the version of the exporter, as set when it was built
+type Collector struct {
+	logger *zap.SugaredLogger
+}
+
+func NewCollector(logger *zap.SugaredLogger) *Collector {
+	logger = getComponentLogger(logger, "collector")
+
+	return &Collector{
+		logger: logger,
+	}
+}
+
+func (c *Collector) Describe(descsChan chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, descsChan)
+}
+
+func (c *Collector) Collect(metricsChan chan<- prometheus.Metric) {
+	metricsChan <- prometheus.MustNewConstMetric(gaugeInfoDesc,
+		prometheus.GaugeValue,
+		metrics.EnumGaugeValuePresent.GaugeValue(),
+		version.Version,   // `version` label
+		version.Revision,  // `revision` label
+		version.GoVersion) // `goversion` label
+
+	c.logger.Infow("collected metric",
+		"version", version.Version,
+		"revision", version.Revision,
+		"goversion", version.GoVersion,
+		"metric", gaugeInfoFQName)
+}
@@ -0,0 +1,8 @@
+package build
+
+import "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+
+var (
+	infoEnumGauge = metrics.NewEnumGauge(metrics.PresentMetricsGaugeValues,
+		"Information about the build of the exporter")
+)
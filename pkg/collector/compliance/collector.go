@@ -0,0 +1,143 @@
+package compliance
+
+import (
+	"sync"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/compliance"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/connector"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	namespace = "fivetran"
+	subsystem = "connector"
+
+	gaugeViolationsName    = "compliance_violations"
+	counterErrorsTotalName = "compliance_errors_total"
+)
+
+var (
+	gaugeViolationsFQName = prometheus.BuildFQName(namespace, subsystem, gaugeViolationsName)
+	gaugeViolationsDesc   = prometheus.NewDesc(
+		gaugeViolationsFQName,
+		"Number of columns or tables of a connector violating a compliance rule",
+		[]string{"group_name", "name", "rule"},
+		prometheus.Labels{})
+)
+
+type Collector struct {
+	Checker compliance.Checker
+	Listers []connector.Lister
+
+	lock               *sync.RWMutex
+	counterErrorsTotal *prometheus.CounterVec
+	logger             *zap.SugaredLogger
+}
+
+func NewCollector(logger *zap.SugaredLogger,
+	registerer prometheus.Registerer,
+	checker compliance.Checker,
+	listers []connector.Lister) *Collector {
+	logger = getComponentLogger(logger, "collector")
+
+	counterErrorsTotal := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      counterErrorsTotalName,
+		Help:      "Total errors encountered checking connector compliance",
+	},
+		[]string{"group_name"})
+	registerer.MustRegister(counterErrorsTotal)
+
+	for _, lister := range listers {
+		// Initialise the error counter to zero for all group names
+		counterErrorsTotal.WithLabelValues(lister.GetGroupName()).Add(0)
+	}
+
+	return &Collector{
+		Checker:            checker,
+		Listers:            listers,
+		lock:               new(sync.RWMutex),
+		counterErrorsTotal: counterErrorsTotal,
+		logger:             logger,
+	}
+}
+
+func (c *Collector) Describe(descsChan chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, descsChan)
+}
+
+func (c *Collector) Collect(metricsChan chan<- prometheus.Metric) {
+	c.lock.RLock()
+	checker := c.Checker
+	listers := c.Listers
+	c.lock.RUnlock()
+
+	waitGroup := new(sync.WaitGroup)
+	waitGroup.Add(len(listers))
+	for _, lister := range listers {
+		go c.collectForLister(checker, lister, metricsChan, waitGroup)
+	}
+	waitGroup.Wait()
+}
+
+// SetSources atomically replaces the checker and listers, e.g. when the config is
+// reloaded. The error counters of groups which are no longer listed are removed.
+func (c *Collector) SetSources(checker compliance.Checker, listers []connector.Lister) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	groupNames := make(map[string]struct{}, len(listers))
+	for _, lister := range listers {
+		groupNames[lister.GetGroupName()] = struct{}{}
+
+		// Initialise the error counter to zero for all group names
+		c.counterErrorsTotal.WithLabelValues(lister.GetGroupName()).Add(0)
+	}
+
+	for _, lister := range c.Listers {
+		if _, ok := groupNames[lister.GetGroupName()]; !ok {
+			c.counterErrorsTotal.DeleteLabelValues(lister.GetGroupName())
+		}
+	}
+
+	c.Checker = checker
+	c.Listers = listers
+}
+
+func (c *Collector) collectForLister(checker compliance.Checker,
+	lister connector.Lister,
+	metricsChan chan<- prometheus.Metric,
+	waitGroup *sync.WaitGroup) {
+	defer waitGroup.Done()
+
+	reports, err := checker.Check(lister)
+	if err != nil {
+		// We do not have to send this metric on the metricsChan as it is already registered
+		// (it is a metric _belonging_ to this collector, rather than _collected_)
+		c.counterErrorsTotal.WithLabelValues(
+			lister.GetGroupName()).Inc() // `group_name` label
+		c.logger.Errorw("checking compliance", "group_name", lister.GetGroupName(), "error", err)
+		return
+	}
+
+	// Create one gauge metric per applicable rule per connector, so that
+	// compliant connectors are reported with a zero value
+	for _, report := range reports {
+		for _, rule := range report.Rules {
+			metricsChan <- prometheus.MustNewConstMetric(gaugeViolationsDesc,
+				prometheus.GaugeValue,
+				float64(report.ViolationCount(rule)),
+				report.GroupName, // `group_name` label
+				report.Name,      // `name` label
+				rule.Name)        // `rule` label
+
+			c.logger.Infow("collected metric",
+				"group_name", report.GroupName,
+				"name", report.Name,
+				"rule", rule.Name,
+				"metric", gaugeViolationsFQName)
+		}
+	}
+}
@@ -1,10 +1,16 @@
 package connector
 
 import (
+	"context"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/scrape"
 	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/connector"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/metadata"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/refresh"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
@@ -25,6 +31,9 @@ const (
 	gaugeWarningCountName     = "warning_count"
 	gaugeInHistoricalSyncName = "in_historical_sync"
 	counterErrorsTotalName    = "errors_total"
+
+	// The name of the collector in the collector self-metrics
+	collectorName = "connector"
 )
 
 var (
@@ -86,19 +95,30 @@ var (
 	gaugeInfo       = prometheus.NewDesc(
 		gaugeInfoFQName,
 		infoEnumGauge.Describe(),
-		[]string{"group_name", "group_id", "name", "id", "service"},
+		[]string{"group_name", "group_id", "name", "id", "service", "service_name", "service_type"},
 		prometheus.Labels{})
 )
 
 type Collector struct {
-	Listers []connector.Lister
+	Listers       []connector.Lister
+	ServiceLooker metadata.Looker
 
+	lock               *sync.RWMutex
 	counterErrorsTotal *prometheus.CounterVec
+	scrapeMetrics      *scrape.Metrics
+	refreshTracker     *refresh.Tracker
+	refreshInterval    time.Duration
+	connectors         map[string][]*connector.Connector // Keyed by group name
 	collectFuncs       []collectFunc
 	logger             *zap.SugaredLogger
 }
 
-func NewCollector(logger *zap.SugaredLogger, listers []connector.Lister) (*Collector, error) {
+func NewCollector(logger *zap.SugaredLogger,
+	registerer prometheus.Registerer,
+	listers []connector.Lister,
+	serviceLooker metadata.Looker,
+	scrapeMetrics *scrape.Metrics,
+	refreshInterval time.Duration) (*Collector, error) {
 	logger = getComponentLogger(logger, "collector")
 
 	counterErrorsTotal := prometheus.NewCounterVec(prometheus.CounterOpts{
@@ -108,7 +128,7 @@ func NewCollector(logger *zap.SugaredLogger, listers []connector.Lister) (*Colle
 		Help:      "Total errors encountered querying connectors",
 	},
 		[]string{"group_name"})
-	prometheus.MustRegister(counterErrorsTotal)
+	registerer.MustRegister(counterErrorsTotal)
 
 	for _, lister := range listers {
 		// Initialise the error counter to zero for all group names
@@ -117,7 +137,13 @@ func NewCollector(logger *zap.SugaredLogger, listers []connector.Lister) (*Colle
 
 	collector := &Collector{
 		Listers:            listers,
+		lock:               new(sync.RWMutex),
+		ServiceLooker:      serviceLooker,
 		counterErrorsTotal: counterErrorsTotal,
+		scrapeMetrics:      scrapeMetrics,
+		refreshTracker:     refresh.NewTracker(),
+		refreshInterval:    refreshInterval,
+		connectors:         make(map[string][]*connector.Connector),
 		logger:             logger,
 	}
 
@@ -142,26 +168,120 @@ func (c *Collector) Describe(descsChan chan<- *prometheus.Desc) {
 }
 
 func (c *Collector) Collect(metricsChan chan<- prometheus.Metric) {
+	start := time.Now()
+
+	c.lock.RLock()
+	listers := c.Listers
+	c.lock.RUnlock()
+
+	// The collection fails if listing the connectors of any group fails
+	failures := new(int32)
 	waitGroup := new(sync.WaitGroup)
-	waitGroup.Add(len(c.Listers))
-	for _, lister := range c.Listers {
-		go c.collectForLister(lister, metricsChan, waitGroup)
+	waitGroup.Add(len(listers))
+	for _, lister := range listers {
+		go c.collectForLister(lister, metricsChan, waitGroup, failures)
 	}
 	waitGroup.Wait()
+
+	// The scrape metrics are registered separately, so are set rather than collected
+	c.scrapeMetrics.Set(collectorName, time.Since(start), atomic.LoadInt32(failures) == 0)
+}
+
+// Run lists the connectors of each listed group at startup and then at every refresh
+// interval, so that the refresh states do not depend on the collector being scraped.
+// Scrapes list the connectors too, and record their outcome in the same way.
+func (c *Collector) Run(ctx context.Context) {
+	for {
+		c.refresh()
+
+		c.lock.RLock()
+		timer := time.NewTimer(c.refreshInterval)
+		c.lock.RUnlock()
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// SetRefreshInterval atomically replaces the refresh interval, e.g. when the config is
+// reloaded. The new interval takes effect after the current interval has elapsed.
+func (c *Collector) SetRefreshInterval(refreshInterval time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.refreshInterval = refreshInterval
+}
+
+// RefreshStates returns the outcome of the most recent listings of the connectors
+// of each listed group, as listed in the background and on each scrape
+func (c *Collector) RefreshStates() []*refresh.State {
+	c.lock.RLock()
+	listers := c.Listers
+	c.lock.RUnlock()
+
+	states := make([]*refresh.State, 0, len(listers))
+	for _, lister := range listers {
+		states = append(states, c.refreshTracker.State(lister.GetGroupName()))
+	}
+
+	return states
+}
+
+// Connectors returns the connectors of each listed group as of the most recent
+// successful listing of the group, as listed in the background and on each scrape
+func (c *Collector) Connectors() []*connector.Connector {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	connectors := make([]*connector.Connector, 0)
+	for _, lister := range c.Listers {
+		connectors = append(connectors, c.connectors[lister.GetGroupName()]...)
+	}
+
+	return connectors
+}
+
+// SetListers atomically replaces the listers, e.g. when the config is reloaded.
+// The error counters, refresh states and connectors of groups which are no longer
+// listed are removed.
+func (c *Collector) SetListers(listers []connector.Lister) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	groupNames := make(map[string]struct{}, len(listers))
+	retainedGroupNames := make([]string, 0, len(listers))
+	for _, lister := range listers {
+		groupNames[lister.GetGroupName()] = struct{}{}
+		retainedGroupNames = append(retainedGroupNames, lister.GetGroupName())
+
+		// Initialise the error counter to zero for all group names
+		c.counterErrorsTotal.WithLabelValues(lister.GetGroupName()).Add(0)
+	}
+
+	for _, lister := range c.Listers {
+		if _, ok := groupNames[lister.GetGroupName()]; !ok {
+			c.counterErrorsTotal.DeleteLabelValues(lister.GetGroupName())
+			delete(c.connectors, lister.GetGroupName())
+		}
+	}
+
+	c.refreshTracker.Retain(retainedGroupNames)
+	c.Listers = listers
 }
 
 func (c *Collector) collectForLister(lister connector.Lister,
 	metricsChan chan<- prometheus.Metric,
-	waitGroup *sync.WaitGroup) {
+	waitGroup *sync.WaitGroup,
+	failures *int32) {
 	defer waitGroup.Done()
 
-	connectors, err := lister.List()
+	connectors, err := c.list(lister)
 	if err != nil {
-		// We do not have to send this metric on the metricsChan as it is already registered
-		// (it is a metric _belonging_ to this collector, rather than _collected_)
-		c.counterErrorsTotal.WithLabelValues(
-			lister.GetGroupName()).Inc() // `group_name` label
-		c.logger.Errorw("listing connectors", "group_name", lister.GetGroupName(), "error", err)
+		atomic.AddInt32(failures, 1)
 		return
 	}
 
@@ -177,6 +297,44 @@ func (c *Collector) collectForLister(lister connector.Lister,
 	collectFuncWaitGroup.Wait()
 }
 
+func (c *Collector) refresh() {
+	c.lock.RLock()
+	listers := c.Listers
+	c.lock.RUnlock()
+
+	waitGroup := new(sync.WaitGroup)
+	waitGroup.Add(len(listers))
+	for _, lister := range listers {
+		go func(lister connector.Lister) {
+			defer waitGroup.Done()
+			_, _ = c.list(lister)
+		}(lister)
+	}
+	waitGroup.Wait()
+}
+
+// list lists the connectors of the group, recording the outcome in the refresh state
+// and the error counter
+func (c *Collector) list(lister connector.Lister) ([]*connector.Connector, error) {
+	connectors, err := lister.List()
+	if err != nil {
+		// We do not have to send this metric on the metricsChan as it is already registered
+		// (it is a metric _belonging_ to this collector, rather than _collected_)
+		c.counterErrorsTotal.WithLabelValues(
+			lister.GetGroupName()).Inc() // `group_name` label
+		c.refreshTracker.Failed(lister.GetGroupName(), err)
+		c.logger.Errorw("listing connectors", "group_name", lister.GetGroupName(), "error", err)
+		return nil, err
+	}
+	c.refreshTracker.Succeeded(lister.GetGroupName())
+
+	c.lock.Lock()
+	c.connectors[lister.GetGroupName()] = connectors
+	c.lock.Unlock()
+
+	return connectors, nil
+}
+
 func (c *Collector) collectPaused(connectors []*connector.Connector,
 	metricsChan chan<- prometheus.Metric,
 	waitGroup *sync.WaitGroup) {
@@ -292,6 +450,13 @@ func (c *Collector) collectInfo(connectors []*connector.Connector,
 
 	// Create one gauge metric per connector
 	for _, conn := range connectors {
+		// The service name and type are left empty if the service is not (yet) known
+		var serviceName, serviceType string
+		if connectorType, ok := c.ServiceLooker.LookUp(conn.Service); ok {
+			serviceName = connectorType.Name
+			serviceType = connectorType.Type
+		}
+
 		metricsChan <- prometheus.MustNewConstMetric(gaugeInfo,
 			prometheus.GaugeValue,
 			metrics.EnumGaugeValuePresent.GaugeValue(),
@@ -299,7 +464,9 @@ func (c *Collector) collectInfo(connectors []*connector.Connector,
 			conn.GroupID,   // `group_id` label
 			conn.Name,      // `name` label
 			conn.ID,        // `id` label
-			conn.Service)   // `service` label
+			conn.Service,   // `service` label
+			serviceName,    // `service_name` label
+			serviceType)    // `service_type` label
 
 		c.logger.Infow("collected metric",
 			"group_name", conn.GroupName,
@@ -307,6 +474,8 @@ func (c *Collector) collectInfo(connectors []*connector.Connector,
 			"name", conn.Name,
 			"id", conn.ID,
 			"service", conn.Service,
+			"service_name", serviceName,
+			"service_type", serviceType,
 			"metric", gaugeInfoFQName)
 	}
 }
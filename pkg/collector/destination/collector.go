@@ -1,10 +1,15 @@
 package destination
 
 import (
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/scrape"
 	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/destination"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/metadata"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
@@ -15,9 +20,14 @@ const (
 	namespace = "fivetran"
 	subsystem = "destination"
 
-	gaugeSetupStatusName   = "setup_status"
-	gaugeInfoName          = "info"
-	counterErrorsTotalName = "errors_total"
+	gaugeSetupStatusName            = "setup_status"
+	gaugeSetupTestStatusName        = "setup_test_status"
+	gaugeInfoName                   = "info"
+	counterErrorsTotalName          = "errors_total"
+	counterDiscoveryErrorsTotalName = "discovery_errors_total"
+
+	// The name of the collector in the collector self-metrics
+	collectorName = "destination"
 )
 
 var (
@@ -27,22 +37,53 @@ var (
 		setupStatusEnumGauge.Describe(),
 		[]string{"group_name", "name"},
 		prometheus.Labels{})
+	gaugeSetupTestStatusFQName = prometheus.BuildFQName(namespace, subsystem, gaugeSetupTestStatusName)
+	gaugeSetupTestStatusDesc   = prometheus.NewDesc(
+		gaugeSetupTestStatusFQName,
+		setupTestStatusEnumGauge.Describe(),
+		[]string{"group_name", "name", "test"},
+		prometheus.Labels{})
 	gaugeInfoFQName = prometheus.BuildFQName(namespace, subsystem, gaugeInfoName)
 	gaugeInfoDesc   = prometheus.NewDesc(
 		gaugeInfoFQName,
 		infoEnumGauge.Describe(),
-		[]string{"group_name", "group_id", "name", "id", "service"},
+		[]string{"group_name",
+			"group_id",
+			"name",
+			"id",
+			"service",
+			"service_name",
+			"service_type",
+			"region",
+			"time_zone_offset",
+			"networking_method",
+			"agent_id",
+			"daylight_saving_time_enabled"},
 		prometheus.Labels{})
 )
 
+// Collector collects the destinations of the describers (one per configured group),
+// and of the listers (each discovering destinations across many groups)
 type Collector struct {
-	Describers         []destination.Describer
-	counterErrorsTotal *prometheus.CounterVec
-	collectFuncs       []collectFunc
-	logger             *zap.SugaredLogger
+	Describers                  []destination.Describer
+	Listers                     []destination.Lister
+	ServiceLooker               metadata.Looker
+	lock                        *sync.RWMutex
+	counterErrorsTotal          *prometheus.CounterVec
+	counterDiscoveryErrorsTotal prometheus.Counter
+	scrapeMetrics               *scrape.Metrics
+	describedDestinations       map[string]*destination.Destination // Keyed by group name
+	discoveredDestinations      map[destination.Lister][]*destination.Destination
+	collectFuncs                []collectFunc
+	logger                      *zap.SugaredLogger
 }
 
-func NewCollector(logger *zap.SugaredLogger, describers []destination.Describer) *Collector {
+func NewCollector(logger *zap.SugaredLogger,
+	registerer prometheus.Registerer,
+	describers []destination.Describer,
+	listers []destination.Lister,
+	serviceLooker metadata.Looker,
+	scrapeMetrics *scrape.Metrics) *Collector {
 	logger = getComponentLogger(logger, "collector")
 
 	counterErrorsTotal := prometheus.NewCounterVec(prometheus.CounterOpts{
@@ -52,21 +93,38 @@ func NewCollector(logger *zap.SugaredLogger, describers []destination.Describer)
 		Help:      "Total errors encountered querying destination",
 	},
 		[]string{"group_name"})
-	prometheus.MustRegister(counterErrorsTotal)
+	registerer.MustRegister(counterErrorsTotal)
 
 	for _, describer := range describers {
 		// Initialise the error counter to zero for all group names
 		counterErrorsTotal.WithLabelValues(describer.GetGroupName()).Add(0)
 	}
 
+	// Errors discovering destinations cannot be attributed to a group
+	counterDiscoveryErrorsTotal := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      counterDiscoveryErrorsTotalName,
+		Help:      "Total errors encountered discovering destinations",
+	})
+	registerer.MustRegister(counterDiscoveryErrorsTotal)
+
 	collector := &Collector{
-		Describers:         describers,
-		counterErrorsTotal: counterErrorsTotal,
-		logger:             logger,
+		Describers:                  describers,
+		Listers:                     listers,
+		ServiceLooker:               serviceLooker,
+		lock:                        new(sync.RWMutex),
+		counterErrorsTotal:          counterErrorsTotal,
+		counterDiscoveryErrorsTotal: counterDiscoveryErrorsTotal,
+		scrapeMetrics:               scrapeMetrics,
+		describedDestinations:       make(map[string]*destination.Destination),
+		discoveredDestinations:      make(map[destination.Lister][]*destination.Destination),
+		logger:                      logger,
 	}
 
 	collectFuncs := []collectFunc{
 		collector.collectSetupStatus,
+		collector.collectSetupTestStatus,
 		collector.collectInfo,
 	}
 	collector.collectFuncs = collectFuncs
@@ -79,21 +137,84 @@ func (c *Collector) Describe(descsChan chan<- *prometheus.Desc) {
 }
 
 func (c *Collector) Collect(metricsChan chan<- prometheus.Metric) {
+	start := time.Now()
+
+	c.lock.RLock()
+	describers := c.Describers
+	listers := c.Listers
+	c.lock.RUnlock()
+
+	// The collection fails if any description or listing of destinations fails
+	failures := new(int32)
 	waitGroup := new(sync.WaitGroup)
-	waitGroup.Add(len(c.Describers))
-	for _, describer := range c.Describers {
-		go c.collectForDescriber(describer, metricsChan, waitGroup)
+	waitGroup.Add(len(describers) + len(listers))
+	for _, describer := range describers {
+		go c.collectForDescriber(describer, metricsChan, waitGroup, failures)
+	}
+	for _, lister := range listers {
+		go c.collectForLister(lister, metricsChan, waitGroup, failures)
 	}
 	waitGroup.Wait()
+
+	// The scrape metrics are registered separately, so are set rather than collected
+	c.scrapeMetrics.Set(collectorName, time.Since(start), atomic.LoadInt32(failures) == 0)
+}
+
+// Destinations returns the destinations of each described group and of each lister,
+// as of the most recent successful description or listing, as made on each scrape
+func (c *Collector) Destinations() []*destination.Destination {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	destinations := make([]*destination.Destination, 0)
+	for _, describer := range c.Describers {
+		if dest, ok := c.describedDestinations[describer.GetGroupName()]; ok {
+			destinations = append(destinations, dest)
+		}
+	}
+	for _, lister := range c.Listers {
+		destinations = append(destinations, c.discoveredDestinations[lister]...)
+	}
+
+	return destinations
+}
+
+// SetSources atomically replaces the describers and listers, e.g. when the config is
+// reloaded. The error counters and destinations of groups which are no longer
+// described, and the destinations of the replaced listers, are removed.
+func (c *Collector) SetSources(describers []destination.Describer, listers []destination.Lister) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	groupNames := make(map[string]struct{}, len(describers))
+	for _, describer := range describers {
+		groupNames[describer.GetGroupName()] = struct{}{}
+
+		// Initialise the error counter to zero for all group names
+		c.counterErrorsTotal.WithLabelValues(describer.GetGroupName()).Add(0)
+	}
+
+	for _, describer := range c.Describers {
+		if _, ok := groupNames[describer.GetGroupName()]; !ok {
+			c.counterErrorsTotal.DeleteLabelValues(describer.GetGroupName())
+			delete(c.describedDestinations, describer.GetGroupName())
+		}
+	}
+
+	c.discoveredDestinations = make(map[destination.Lister][]*destination.Destination)
+	c.Describers = describers
+	c.Listers = listers
 }
 
 func (c *Collector) collectForDescriber(describer destination.Describer,
 	metricsChan chan<- prometheus.Metric,
-	waitGroup *sync.WaitGroup) {
+	waitGroup *sync.WaitGroup,
+	failures *int32) {
 	defer waitGroup.Done()
 
 	destination, err := describer.Describe()
 	if err != nil {
+		atomic.AddInt32(failures, 1)
 		// We do not have to send this metric on the metricsChan as it is already registered
 		// (it is a metric _belonging_ to this collector, rather than _collected_)
 		c.counterErrorsTotal.WithLabelValues(
@@ -102,6 +223,40 @@ func (c *Collector) collectForDescriber(describer destination.Describer,
 		return
 	}
 
+	c.lock.Lock()
+	c.describedDestinations[describer.GetGroupName()] = destination
+	c.lock.Unlock()
+
+	c.collectForDestination(destination, metricsChan)
+}
+
+func (c *Collector) collectForLister(lister destination.Lister,
+	metricsChan chan<- prometheus.Metric,
+	waitGroup *sync.WaitGroup,
+	failures *int32) {
+	defer waitGroup.Done()
+
+	destinations, err := lister.List()
+	if err != nil {
+		atomic.AddInt32(failures, 1)
+		// We do not have to send this metric on the metricsChan as it is already registered
+		// (it is a metric _belonging_ to this collector, rather than _collected_)
+		c.counterDiscoveryErrorsTotal.Inc()
+		c.logger.Errorw("listing destinations", "error", err)
+		return
+	}
+
+	c.lock.Lock()
+	c.discoveredDestinations[lister] = destinations
+	c.lock.Unlock()
+
+	for _, destination := range destinations {
+		c.collectForDestination(destination, metricsChan)
+	}
+}
+
+func (c *Collector) collectForDestination(destination *destination.Destination,
+	metricsChan chan<- prometheus.Metric) {
 	collectFuncWaitGroup := new(sync.WaitGroup)
 	collectFuncWaitGroup.Add(len(c.collectFuncs))
 	for _, collectFunc := range c.collectFuncs {
@@ -109,7 +264,7 @@ func (c *Collector) collectForDescriber(describer destination.Describer,
 		// TODO: Handle errors and increment error counter.
 		// Currently the collectFuncs do not return an error, as
 		// we trust that the data they work on is 100% legit, as
-		// it was sanity-checked by the describer already
+		// it was sanity-checked by the describer or lister already
 	}
 	collectFuncWaitGroup.Wait()
 }
@@ -139,19 +294,92 @@ func (c *Collector) collectSetupStatus(dest *destination.Destination,
 		"metric", gaugeSetupStateFQName)
 }
 
+func (c *Collector) collectSetupTestStatus(dest *destination.Destination,
+	metricsChan chan<- prometheus.Metric,
+	waitGroup *sync.WaitGroup) {
+	defer waitGroup.Done()
+
+	// The title is free text, so more than one setup test may be reported with the same
+	// title. Only the worst status of each title is reported, else the duplicate series
+	// would fail the whole scrape.
+	titles := make([]string, 0, len(dest.SetupTests))
+	statusesByTitle := make(map[string]destination.SetupTestStatus, len(dest.SetupTests))
+	for _, setupTest := range dest.SetupTests {
+		status, ok := statusesByTitle[setupTest.Title]
+		if !ok {
+			statusesByTitle[setupTest.Title] = setupTest.Status
+			titles = append(titles, setupTest.Title)
+			continue
+		}
+
+		c.logger.Warnw("duplicate setup test",
+			"group_name", dest.GroupName,
+			"name", dest.Name,
+			"test", setupTest.Title)
+
+		if setupTestStatusSeverities[setupTest.Status] > setupTestStatusSeverities[status] {
+			statusesByTitle[setupTest.Title] = setupTest.Status
+		}
+	}
+
+	// Create one gauge metric per setup test. There are none if setup tests have not been run.
+	for _, title := range titles {
+		value := setupTestStatusGaugeValuePassed
+		switch statusesByTitle[title] {
+		case destination.SetupTestStatusWarning:
+			value = setupTestStatusGaugeValueWarning
+		case destination.SetupTestStatusSkipped:
+			value = setupTestStatusGaugeValueSkipped
+		case destination.SetupTestStatusFailed:
+			value = setupTestStatusGaugeValueFailed
+		case destination.SetupTestStatusJobFailed:
+			value = setupTestStatusGaugeValueJobFailed
+		case destination.SetupTestStatusUnknown:
+			value = setupTestStatusGaugeValueUnknown
+		}
+
+		metricsChan <- prometheus.MustNewConstMetric(gaugeSetupTestStatusDesc,
+			prometheus.GaugeValue,
+			value.GaugeValue(),
+			dest.GroupName, // `group_name` label
+			dest.Name,      // `name` label
+			title)          // `test` label
+
+		c.logger.Infow("collected metric",
+			"group_name", dest.GroupName,
+			"name", dest.Name,
+			"test", title,
+			"metric", gaugeSetupTestStatusFQName)
+	}
+}
+
 func (c *Collector) collectInfo(dest *destination.Destination,
 	metricsChan chan<- prometheus.Metric,
 	waitGroup *sync.WaitGroup) {
 	defer waitGroup.Done()
 
+	// The service name and type are left empty if the service is not (yet) known
+	var serviceName, serviceType string
+	if connectorType, ok := c.ServiceLooker.LookUp(dest.Service); ok {
+		serviceName = connectorType.Name
+		serviceType = connectorType.Type
+	}
+
 	metricsChan <- prometheus.MustNewConstMetric(gaugeInfoDesc,
 		prometheus.GaugeValue,
 		metrics.EnumGaugeValuePresent.GaugeValue(),
-		dest.GroupName, // `group_name` label
-		dest.GroupID,   // `group_id` label
-		dest.Name,      // `name` label
-		dest.ID,        // `id` label
-		dest.Service)   // `service` label
+		dest.GroupName,        // `group_name` label
+		dest.GroupID,          // `group_id` label
+		dest.Name,             // `name` label
+		dest.ID,               // `id` label
+		dest.Service,          // `service` label
+		serviceName,           // `service_name` label
+		serviceType,           // `service_type` label
+		dest.Region,           // `region` label
+		dest.TimeZoneOffset,   // `time_zone_offset` label
+		dest.NetworkingMethod, // `networking_method` label
+		dest.AgentID,          // `agent_id` label
+		strconv.FormatBool(dest.DaylightSavingTimeEnabled)) // `daylight_saving_time_enabled` label
 
 	c.logger.Infow("collected metric",
 		"group_name", dest.GroupName,
@@ -159,5 +387,12 @@ func (c *Collector) collectInfo(dest *destination.Destination,
 		"name", dest.Name,
 		"id", dest.ID,
 		"service", dest.Service,
+		"service_name", serviceName,
+		"service_type", serviceType,
+		"region", dest.Region,
+		"time_zone_offset", dest.TimeZoneOffset,
+		"networking_method", dest.NetworkingMethod,
+		"agent_id", dest.AgentID,
+		"daylight_saving_time_enabled", dest.DaylightSavingTimeEnabled,
 		"metric", gaugeInfoFQName)
 }
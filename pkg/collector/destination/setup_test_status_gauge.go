@@ -0,0 +1,35 @@
+package destination
+
+import (
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/destination"
+)
+
+var (
+	setupTestStatusGaugeValuePassed    = metrics.NewEnumGaugeValue("passed", 0)
+	setupTestStatusGaugeValueWarning   = metrics.NewEnumGaugeValue("warning", 1)
+	setupTestStatusGaugeValueSkipped   = metrics.NewEnumGaugeValue("skipped", 2)
+	setupTestStatusGaugeValueFailed    = metrics.NewEnumGaugeValue("failed", 3)
+	setupTestStatusGaugeValueJobFailed = metrics.NewEnumGaugeValue("job_failed", 4)
+	setupTestStatusGaugeValueUnknown   = metrics.NewEnumGaugeValue("unknown", 5)
+
+	setupTestStatusEnumGauge = metrics.NewEnumGauge([]*metrics.EnumGaugeValue{
+		setupTestStatusGaugeValuePassed,
+		setupTestStatusGaugeValueWarning,
+		setupTestStatusGaugeValueSkipped,
+		setupTestStatusGaugeValueFailed,
+		setupTestStatusGaugeValueJobFailed,
+		setupTestStatusGaugeValueUnknown,
+	}, "Result of the last run of a destination setup test")
+)
+
+// setupTestStatusSeverities orders the setup test statuses from best to worst, for
+// choosing between setup tests reported more than once with the same title
+var setupTestStatusSeverities = map[destination.SetupTestStatus]int{
+	destination.SetupTestStatusPassed:    0,
+	destination.SetupTestStatusSkipped:   1,
+	destination.SetupTestStatusWarning:   2,
+	destination.SetupTestStatusUnknown:   3,
+	destination.SetupTestStatusFailed:    4,
+	destination.SetupTestStatusJobFailed: 5,
+}
@@ -0,0 +1,122 @@
+package externallogging
+
+import (
+	"sync"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/externallogging"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/group"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	namespace = "fivetran"
+	subsystem = "external_logging"
+
+	gaugeEnabledName       = "enabled"
+	counterErrorsTotalName = "errors_total"
+)
+
+var (
+	gaugeEnabledFQName = prometheus.BuildFQName(namespace, subsystem, gaugeEnabledName)
+	gaugeEnabledDesc   = prometheus.NewDesc(
+		gaugeEnabledFQName,
+		enabledEnumGauge.Describe(),
+		[]string{"group_name", "id", "service"},
+		prometheus.Labels{})
+)
+
+// Collector reports the external logging services of the collected groups
+type Collector struct {
+	Lister externallogging.Lister
+	Groups []*group.Group
+
+	lock               *sync.RWMutex
+	counterErrorsTotal prometheus.Counter
+	logger             *zap.SugaredLogger
+}
+
+func NewCollector(logger *zap.SugaredLogger,
+	registerer prometheus.Registerer,
+	lister externallogging.Lister,
+	groups []*group.Group) *Collector {
+	logger = getComponentLogger(logger, "collector")
+
+	counterErrorsTotal := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      counterErrorsTotalName,
+		Help:      "Total errors encountered listing external logging services",
+	})
+	registerer.MustRegister(counterErrorsTotal)
+
+	return &Collector{
+		Lister:             lister,
+		Groups:             groups,
+		lock:               new(sync.RWMutex),
+		counterErrorsTotal: counterErrorsTotal,
+		logger:             logger,
+	}
+}
+
+func (c *Collector) Describe(descsChan chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, descsChan)
+}
+
+// SetSources atomically replaces the lister and collected groups, e.g. when the
+// config is reloaded
+func (c *Collector) SetSources(lister externallogging.Lister, groups []*group.Group) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.Lister = lister
+	c.Groups = groups
+}
+
+func (c *Collector) Collect(metricsChan chan<- prometheus.Metric) {
+	c.lock.RLock()
+	lister := c.Lister
+	groups := c.Groups
+	c.lock.RUnlock()
+
+	logServices, err := lister.List()
+	if err != nil {
+		// We do not have to send this metric on the metricsChan as it is already registered
+		// (it is a metric _belonging_ to this collector, rather than _collected_)
+		c.counterErrorsTotal.Inc()
+		c.logger.Errorw("listing external logging services", "error", err)
+		return
+	}
+
+	groupNames := make(map[string]string, len(groups)) // Keyed by group ID
+	for _, g := range groups {
+		groupNames[g.ID] = g.Name
+	}
+
+	// Create one gauge metric per external logging service
+	for _, ls := range logServices {
+		groupName, ok := groupNames[ls.GroupID]
+		if !ok {
+			continue
+		}
+
+		value := metrics.EnumGaugeValueFalse
+		if ls.Enabled {
+			value = metrics.EnumGaugeValueTrue
+		}
+
+		metricsChan <- prometheus.MustNewConstMetric(gaugeEnabledDesc,
+			prometheus.GaugeValue,
+			value.GaugeValue(),
+			groupName,  // `group_name` label
+			ls.ID,      // `id` label
+			ls.Service) // `service` label
+
+		c.logger.Infow("collected metric",
+			"group_name", groupName,
+			"id", ls.ID,
+			"service", ls.Service,
+			"metric", gaugeEnabledFQName)
+	}
+}
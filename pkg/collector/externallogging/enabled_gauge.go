@@ -0,0 +1,8 @@
+package externallogging
+
+import "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+
+var (
+	enabledEnumGauge = metrics.NewEnumGauge(metrics.BooleanMetricsGaugeValues,
+		"Whether or not an external logging service is enabled")
+)
@@ -0,0 +1,155 @@
+package privatelink
+
+import (
+	"sync"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/group"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/privatelink"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	namespace = "fivetran"
+	subsystem = "private_link"
+
+	gaugeStateName         = "state"
+	gaugeInfoName          = "info"
+	counterErrorsTotalName = "errors_total"
+)
+
+var (
+	gaugeStateFQName = prometheus.BuildFQName(namespace, subsystem, gaugeStateName)
+	gaugeStateDesc   = prometheus.NewDesc(
+		gaugeStateFQName,
+		stateEnumGauge.Describe(),
+		[]string{"group_name", "name"},
+		prometheus.Labels{})
+	gaugeInfoFQName = prometheus.BuildFQName(namespace, subsystem, gaugeInfoName)
+	gaugeInfoDesc   = prometheus.NewDesc(
+		gaugeInfoFQName,
+		infoEnumGauge.Describe(),
+		[]string{"group_name", "name", "id", "service", "region", "cloud_provider"},
+		prometheus.Labels{})
+)
+
+// Collector reports the private links of the account and of the collected groups.
+// Private links which are not specific to a group have an empty group name.
+type Collector struct {
+	Lister privatelink.Lister
+	Groups []*group.Group
+
+	lock               *sync.RWMutex
+	counterErrorsTotal prometheus.Counter
+	logger             *zap.SugaredLogger
+}
+
+func NewCollector(logger *zap.SugaredLogger,
+	registerer prometheus.Registerer,
+	lister privatelink.Lister,
+	groups []*group.Group) *Collector {
+	logger = getComponentLogger(logger, "collector")
+
+	counterErrorsTotal := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      counterErrorsTotalName,
+		Help:      "Total errors encountered listing private links",
+	})
+	registerer.MustRegister(counterErrorsTotal)
+
+	return &Collector{
+		Lister:             lister,
+		Groups:             groups,
+		lock:               new(sync.RWMutex),
+		counterErrorsTotal: counterErrorsTotal,
+		logger:             logger,
+	}
+}
+
+func (c *Collector) Describe(descsChan chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, descsChan)
+}
+
+// SetSources atomically replaces the lister and collected groups, e.g. when the
+// config is reloaded
+func (c *Collector) SetSources(lister privatelink.Lister, groups []*group.Group) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.Lister = lister
+	c.Groups = groups
+}
+
+func (c *Collector) Collect(metricsChan chan<- prometheus.Metric) {
+	c.lock.RLock()
+	lister := c.Lister
+	groups := c.Groups
+	c.lock.RUnlock()
+
+	privateLinks, err := lister.List()
+	if err != nil {
+		// We do not have to send this metric on the metricsChan as it is already registered
+		// (it is a metric _belonging_ to this collector, rather than _collected_)
+		c.counterErrorsTotal.Inc()
+		c.logger.Errorw("listing private links", "error", err)
+		return
+	}
+
+	groupNames := make(map[string]string, len(groups)+1) // Keyed by group ID
+	groupNames[""] = ""                                  // Account private links
+	for _, g := range groups {
+		groupNames[g.ID] = g.Name
+	}
+
+	// Create one gauge metric of each type per private link
+	for _, pl := range privateLinks {
+		groupName, ok := groupNames[pl.GroupID]
+		if !ok {
+			continue
+		}
+
+		value := stateGaugeValueReady
+		switch pl.State {
+		case privatelink.StatePending:
+			value = stateGaugeValuePending
+		case privatelink.StateCreating:
+			value = stateGaugeValueCreating
+		case privatelink.StateDeleting:
+			value = stateGaugeValueDeleting
+		case privatelink.StateFailed:
+			value = stateGaugeValueFailed
+		}
+
+		metricsChan <- prometheus.MustNewConstMetric(gaugeStateDesc,
+			prometheus.GaugeValue,
+			value.GaugeValue(),
+			groupName, // `group_name` label
+			pl.Name)   // `name` label
+
+		c.logger.Infow("collected metric",
+			"group_name", groupName,
+			"name", pl.Name,
+			"metric", gaugeStateFQName)
+
+		metricsChan <- prometheus.MustNewConstMetric(gaugeInfoDesc,
+			prometheus.GaugeValue,
+			metrics.EnumGaugeValuePresent.GaugeValue(),
+			groupName,        // `group_name` label
+			pl.Name,          // `name` label
+			pl.ID,            // `id` label
+			pl.Service,       // `service` label
+			pl.Region,        // `region` label
+			pl.CloudProvider) // `cloud_provider` label
+
+		c.logger.Infow("collected metric",
+			"group_name", groupName,
+			"name", pl.Name,
+			"id", pl.ID,
+			"service", pl.Service,
+			"region", pl.Region,
+			"cloud_provider", pl.CloudProvider,
+			"metric", gaugeInfoFQName)
+	}
+}
@@ -0,0 +1,19 @@
+package privatelink
+
+import "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+
+var (
+	stateGaugeValueReady    = metrics.NewEnumGaugeValue("ready", 0)
+	stateGaugeValuePending  = metrics.NewEnumGaugeValue("pending", 1)
+	stateGaugeValueCreating = metrics.NewEnumGaugeValue("creating", 2)
+	stateGaugeValueDeleting = metrics.NewEnumGaugeValue("deleting", 3)
+	stateGaugeValueFailed   = metrics.NewEnumGaugeValue("failed", 4)
+
+	stateEnumGauge = metrics.NewEnumGauge([]*metrics.EnumGaugeValue{
+		stateGaugeValueReady,
+		stateGaugeValuePending,
+		stateGaugeValueCreating,
+		stateGaugeValueDeleting,
+		stateGaugeValueFailed,
+	}, "Current provisioning state of a private link")
+)
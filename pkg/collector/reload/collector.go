@@ -0,0 +1,72 @@
+package reload
+
+import (
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/reload"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	namespace = "fivetran"
+	subsystem = "exporter"
+
+	gaugeSuccessfulName  = "config_last_reload_successful"
+	gaugeLastSuccessName = "config_last_reload_success_timestamp_seconds"
+)
+
+var (
+	gaugeSuccessfulFQName = prometheus.BuildFQName(namespace, subsystem, gaugeSuccessfulName)
+	gaugeSuccessfulDesc   = prometheus.NewDesc(
+		gaugeSuccessfulFQName,
+		successfulEnumGauge.Describe(),
+		[]string{},
+		prometheus.Labels{})
+	gaugeLastSuccessFQName = prometheus.BuildFQName(namespace, subsystem, gaugeLastSuccessName)
+	gaugeLastSuccessDesc   = prometheus.NewDesc(
+		gaugeLastSuccessFQName,
+		"Time of the last successful reload of the exporter config, in seconds since the epoch",
+		[]string{},
+		prometheus.Labels{})
+)
+
+// Collector reports the outcome of reloads of the exporter config
+type Collector struct {
+	Reporter reload.Reporter
+
+	logger *zap.SugaredLogger
+}
+
+func NewCollector(logger *zap.SugaredLogger, reporter reload.Reporter) *Collector {
+	logger = getComponentLogger(logger, "collector")
+
+	return &Collector{
+		Reporter: reporter,
+		logger:   logger,
+	}
+}
+
+func (c *Collector) Describe(descsChan chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, descsChan)
+}
+
+func (c *Collector) Collect(metricsChan chan<- prometheus.Metric) {
+	successful, lastSuccess := c.Reporter.LastReload()
+
+	value := metrics.EnumGaugeValueFalse
+	if successful {
+		value = metrics.EnumGaugeValueTrue
+	}
+
+	metricsChan <- prometheus.MustNewConstMetric(gaugeSuccessfulDesc,
+		prometheus.GaugeValue,
+		value.GaugeValue())
+
+	c.logger.Infow("collected metric", "metric", gaugeSuccessfulFQName)
+
+	metricsChan <- prometheus.MustNewConstMetric(gaugeLastSuccessDesc,
+		prometheus.GaugeValue,
+		float64(lastSuccess.Unix()))
+
+	c.logger.Infow("collected metric", "metric", gaugeLastSuccessFQName)
+}
@@ -0,0 +1,8 @@
+package reload
+
+import "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+
+var (
+	successfulEnumGauge = metrics.NewEnumGauge(metrics.BooleanMetricsGaugeValues,
+		"Whether or not the last reload of the exporter config was successful")
+)
@@ -0,0 +1,76 @@
+package scrape
+
+import (
+	"time"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	namespace = "fivetran"
+	subsystem = "exporter"
+
+	gaugeDurationName = "collector_duration_seconds"
+	gaugeSuccessName  = "collector_success"
+)
+
+// Metrics are the duration and outcome of the last collection by each collector. The
+// collectors of an account share the metrics, so they are registered once, rather than
+// collected by each collector, which would register the same descriptors twice.
+type Metrics struct {
+	gaugeDuration *prometheus.GaugeVec
+	gaugeSuccess  *prometheus.GaugeVec
+	logger        *zap.SugaredLogger
+}
+
+func NewMetrics(logger *zap.SugaredLogger, registerer prometheus.Registerer) *Metrics {
+	logger = getComponentLogger(logger, "metrics")
+
+	gaugeDuration := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      gaugeDurationName,
+		Help:      "Duration of the last collection by a collector",
+	},
+		[]string{"collector"})
+	registerer.MustRegister(gaugeDuration)
+
+	gaugeSuccess := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      gaugeSuccessName,
+		Help:      successEnumGauge.Describe(),
+	},
+		[]string{"collector"})
+	registerer.MustRegister(gaugeSuccess)
+
+	return &Metrics{
+		gaugeDuration: gaugeDuration,
+		gaugeSuccess:  gaugeSuccess,
+		logger:        logger,
+	}
+}
+
+// Set records the duration and outcome of a collection by the named collector. It is
+// called by the collector at the end of each of its collections, so that the metrics
+// are those of the scrape in which they are collected, unless the collectors are
+// gathered in an order in which the metrics are gathered first.
+func (m *Metrics) Set(collectorName string, duration time.Duration, successful bool) {
+	m.gaugeDuration.WithLabelValues(
+		collectorName).Set(duration.Seconds()) // `collector` label
+
+	value := metrics.EnumGaugeValueFalse
+	if successful {
+		value = metrics.EnumGaugeValueTrue
+	}
+
+	m.gaugeSuccess.WithLabelValues(
+		collectorName).Set(value.GaugeValue()) // `collector` label
+
+	m.logger.Infow("recorded collection",
+		"collector", collectorName,
+		"duration", duration,
+		"successful", successful)
+}
@@ -0,0 +1,80 @@
+package scrape_test
+
+import (
+	"testing"
+	"time"
+
+	connectorcollector "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/connector"
+	destinationcollector "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/destination"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/scrape"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/metadata"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// TestCollectorsRegisterTogether registers the collectors which share the scrape metrics
+// on one registry, as they are registered for each account
+func TestCollectorsRegisterTogether(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	registry := prometheus.NewRegistry()
+	registerer := prometheus.WrapRegistererWith(prometheus.Labels{"account": "default"}, registry)
+	metadataCache := metadata.NewCache(logger, nil, time.Hour, time.Minute, time.Hour)
+	scrapeMetrics := scrape.NewMetrics(logger, registerer)
+
+	connectorCollector, err := connectorcollector.NewCollector(logger,
+		registerer,
+		nil,
+		metadataCache,
+		scrapeMetrics,
+		time.Minute)
+	if err != nil {
+		t.Fatalf("constructing connector collector: %v", err)
+	}
+
+	destinationCollector := destinationcollector.NewCollector(logger,
+		registerer,
+		nil,
+		nil,
+		metadataCache,
+		scrapeMetrics)
+
+	if err := registerer.Register(connectorCollector); err != nil {
+		t.Fatalf("registering connector collector: %v", err)
+	}
+
+	if err := registerer.Register(destinationCollector); err != nil {
+		t.Fatalf("registering destination collector: %v", err)
+	}
+
+	// The scrape metrics are set at the end of each collection, so are only certain to
+	// be gathered from the second gathering
+	if _, err := registry.Gather(); err != nil {
+		t.Fatalf("gathering metrics: %v", err)
+	}
+
+	metricFamilies, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("gathering metrics: %v", err)
+	}
+
+	collectors := make(map[string]bool)
+	for _, metricFamily := range metricFamilies {
+		if metricFamily.GetName() != "fivetran_exporter_collector_success" {
+			continue
+		}
+
+		for _, metric := range metricFamily.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "collector" {
+					collectors[label.GetValue()] = true
+				}
+			}
+		}
+	}
+
+	for _, collector := range []string{"connector", "destination"} {
+		if !collectors[collector] {
+			t.Errorf("no collector_success series for collector %q", collector)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package scrape
+
+import "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+
+var (
+	successEnumGauge = metrics.NewEnumGauge(metrics.BooleanMetricsGaugeValues,
+		"Whether or not the last collection by a collector succeeded")
+)
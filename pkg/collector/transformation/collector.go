@@ -0,0 +1,340 @@
+package transformation
+
+import (
+	"sync"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/transformation"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+type collectFunc func([]*transformation.Transformation, chan<- prometheus.Metric, *sync.WaitGroup)
+
+const (
+	namespace = "fivetran"
+	subsystem = "transformation"
+
+	gaugePausedName           = "paused"
+	gaugeStatusName           = "status"
+	gaugeInfoName             = "info"
+	gaugeLastRunName          = "last_run_timestamp_seconds"
+	gaugeNextRunName          = "next_run_timestamp_seconds"
+	gaugeScheduleIntervalName = "schedule_interval_seconds"
+	counterErrorsTotalName    = "errors_total"
+)
+
+var (
+	// The name is that of the output model, which is only unique within a dbt project,
+	// so the transformations are also labelled by project
+	gaugePausedFQName = prometheus.BuildFQName(namespace, subsystem, gaugePausedName)
+	gaugePausedDesc   = prometheus.NewDesc(
+		gaugePausedFQName,
+		pausedEnumGauge.Describe(),
+		[]string{"group_name", "name", "project_id"},
+		prometheus.Labels{})
+	gaugeStatusFQName = prometheus.BuildFQName(namespace, subsystem, gaugeStatusName)
+	gaugeStatusDesc   = prometheus.NewDesc(
+		gaugeStatusFQName,
+		statusEnumGauge.Describe(),
+		[]string{"group_name", "name", "project_id"},
+		prometheus.Labels{})
+	gaugeLastRunFQName = prometheus.BuildFQName(namespace, subsystem, gaugeLastRunName)
+	gaugeLastRunDesc   = prometheus.NewDesc(
+		gaugeLastRunFQName,
+		"Time a transformation last ran, in seconds since the epoch",
+		[]string{"group_name", "name", "project_id"},
+		prometheus.Labels{})
+	gaugeNextRunFQName = prometheus.BuildFQName(namespace, subsystem, gaugeNextRunName)
+	gaugeNextRunDesc   = prometheus.NewDesc(
+		gaugeNextRunFQName,
+		"Time a transformation is next scheduled to run, in seconds since the epoch",
+		[]string{"group_name", "name", "project_id"},
+		prometheus.Labels{})
+	gaugeScheduleIntervalFQName = prometheus.BuildFQName(namespace, subsystem, gaugeScheduleIntervalName)
+	gaugeScheduleIntervalDesc   = prometheus.NewDesc(
+		gaugeScheduleIntervalFQName,
+		"Interval between runs of a transformation on an interval schedule",
+		[]string{"group_name", "name", "project_id"},
+		prometheus.Labels{})
+	gaugeInfoFQName = prometheus.BuildFQName(namespace, subsystem, gaugeInfoName)
+	gaugeInfoDesc   = prometheus.NewDesc(
+		gaugeInfoFQName,
+		infoEnumGauge.Describe(),
+		[]string{"group_name", "group_id", "name", "id", "project_id", "schedule_type"},
+		prometheus.Labels{})
+)
+
+type Collector struct {
+	Listers []transformation.Lister
+
+	lock               *sync.RWMutex
+	counterErrorsTotal *prometheus.CounterVec
+	collectFuncs       []collectFunc
+	logger             *zap.SugaredLogger
+}
+
+func NewCollector(logger *zap.SugaredLogger, registerer prometheus.Registerer, listers []transformation.Lister) *Collector {
+	logger = getComponentLogger(logger, "collector")
+
+	counterErrorsTotal := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      counterErrorsTotalName,
+		Help:      "Total errors encountered querying transformations",
+	},
+		[]string{"group_name"})
+	registerer.MustRegister(counterErrorsTotal)
+
+	for _, lister := range listers {
+		// Initialise the error counter to zero for all group names
+		counterErrorsTotal.WithLabelValues(lister.GetGroupName()).Add(0)
+	}
+
+	collector := &Collector{
+		Listers:            listers,
+		lock:               new(sync.RWMutex),
+		counterErrorsTotal: counterErrorsTotal,
+		logger:             logger,
+	}
+
+	collectFuncs := []collectFunc{
+		collector.collectPaused,
+		collector.collectStatus,
+		collector.collectLastRun,
+		collector.collectNextRun,
+		collector.collectScheduleInterval,
+		collector.collectInfo,
+	}
+	collector.collectFuncs = collectFuncs
+
+	return collector
+}
+
+func (c *Collector) Describe(descsChan chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, descsChan)
+}
+
+func (c *Collector) Collect(metricsChan chan<- prometheus.Metric) {
+	c.lock.RLock()
+	listers := c.Listers
+	c.lock.RUnlock()
+
+	waitGroup := new(sync.WaitGroup)
+	waitGroup.Add(len(listers))
+	for _, lister := range listers {
+		go c.collectForLister(lister, metricsChan, waitGroup)
+	}
+	waitGroup.Wait()
+}
+
+// SetListers atomically replaces the listers, e.g. when the config is reloaded.
+// The error counters of groups which are no longer listed are removed.
+func (c *Collector) SetListers(listers []transformation.Lister) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	groupNames := make(map[string]struct{}, len(listers))
+	for _, lister := range listers {
+		groupNames[lister.GetGroupName()] = struct{}{}
+
+		// Initialise the error counter to zero for all group names
+		c.counterErrorsTotal.WithLabelValues(lister.GetGroupName()).Add(0)
+	}
+
+	for _, lister := range c.Listers {
+		if _, ok := groupNames[lister.GetGroupName()]; !ok {
+			c.counterErrorsTotal.DeleteLabelValues(lister.GetGroupName())
+		}
+	}
+
+	c.Listers = listers
+}
+
+func (c *Collector) collectForLister(lister transformation.Lister,
+	metricsChan chan<- prometheus.Metric,
+	waitGroup *sync.WaitGroup) {
+	defer waitGroup.Done()
+
+	transformations, err := lister.List()
+	if err != nil {
+		// We do not have to send this metric on the metricsChan as it is already registered
+		// (it is a metric _belonging_ to this collector, rather than _collected_)
+		c.counterErrorsTotal.WithLabelValues(
+			lister.GetGroupName()).Inc() // `group_name` label
+		c.logger.Errorw("listing transformations", "group_name", lister.GetGroupName(), "error", err)
+		return
+	}
+
+	collectFuncWaitGroup := new(sync.WaitGroup)
+	collectFuncWaitGroup.Add(len(c.collectFuncs))
+	for _, collectFunc := range c.collectFuncs {
+		go collectFunc(transformations, metricsChan, collectFuncWaitGroup)
+	}
+	collectFuncWaitGroup.Wait()
+}
+
+func (c *Collector) collectPaused(transformations []*transformation.Transformation,
+	metricsChan chan<- prometheus.Metric,
+	waitGroup *sync.WaitGroup) {
+	defer waitGroup.Done()
+
+	// Create one gauge metric per transformation
+	for _, t := range transformations {
+		value := metrics.EnumGaugeValueFalse
+		if t.Paused {
+			value = metrics.EnumGaugeValueTrue
+		}
+
+		metricsChan <- prometheus.MustNewConstMetric(gaugePausedDesc,
+			prometheus.GaugeValue,
+			value.GaugeValue(),
+			t.GroupName, // `group_name` label
+			t.Name,      // `name` label
+			t.ProjectID) // `project_id` label
+
+		c.logger.Infow("collected metric",
+			"group_name", t.GroupName,
+			"name", t.Name,
+			"project_id", t.ProjectID,
+			"metric", gaugePausedFQName)
+	}
+}
+
+func (c *Collector) collectStatus(transformations []*transformation.Transformation,
+	metricsChan chan<- prometheus.Metric,
+	waitGroup *sync.WaitGroup) {
+	defer waitGroup.Done()
+
+	// Create one gauge metric per transformation
+	for _, t := range transformations {
+		value := statusGaugeValueSucceeded
+		switch t.Status {
+		case transformation.StatusRunning:
+			value = statusGaugeValueRunning
+		case transformation.StatusPending:
+			value = statusGaugeValuePending
+		case transformation.StatusFailed:
+			value = statusGaugeValueFailed
+		}
+
+		metricsChan <- prometheus.MustNewConstMetric(gaugeStatusDesc,
+			prometheus.GaugeValue,
+			value.GaugeValue(),
+			t.GroupName, // `group_name` label
+			t.Name,      // `name` label
+			t.ProjectID) // `project_id` label
+
+		c.logger.Infow("collected metric",
+			"group_name", t.GroupName,
+			"name", t.Name,
+			"project_id", t.ProjectID,
+			"metric", gaugeStatusFQName)
+	}
+}
+
+func (c *Collector) collectLastRun(transformations []*transformation.Transformation,
+	metricsChan chan<- prometheus.Metric,
+	waitGroup *sync.WaitGroup) {
+	defer waitGroup.Done()
+
+	// Create one gauge metric per transformation which has run
+	for _, t := range transformations {
+		if t.LastRun == nil {
+			continue
+		}
+
+		metricsChan <- prometheus.MustNewConstMetric(gaugeLastRunDesc,
+			prometheus.GaugeValue,
+			float64(t.LastRun.Unix()),
+			t.GroupName, // `group_name` label
+			t.Name,      // `name` label
+			t.ProjectID) // `project_id` label
+
+		c.logger.Infow("collected metric",
+			"group_name", t.GroupName,
+			"name", t.Name,
+			"project_id", t.ProjectID,
+			"metric", gaugeLastRunFQName)
+	}
+}
+
+func (c *Collector) collectNextRun(transformations []*transformation.Transformation,
+	metricsChan chan<- prometheus.Metric,
+	waitGroup *sync.WaitGroup) {
+	defer waitGroup.Done()
+
+	// Create one gauge metric per scheduled transformation
+	for _, t := range transformations {
+		if t.NextRun == nil {
+			continue
+		}
+
+		metricsChan <- prometheus.MustNewConstMetric(gaugeNextRunDesc,
+			prometheus.GaugeValue,
+			float64(t.NextRun.Unix()),
+			t.GroupName, // `group_name` label
+			t.Name,      // `name` label
+			t.ProjectID) // `project_id` label
+
+		c.logger.Infow("collected metric",
+			"group_name", t.GroupName,
+			"name", t.Name,
+			"project_id", t.ProjectID,
+			"metric", gaugeNextRunFQName)
+	}
+}
+
+func (c *Collector) collectScheduleInterval(transformations []*transformation.Transformation,
+	metricsChan chan<- prometheus.Metric,
+	waitGroup *sync.WaitGroup) {
+	defer waitGroup.Done()
+
+	// Create one gauge metric per transformation on an interval schedule
+	for _, t := range transformations {
+		if t.IntervalMins == 0 {
+			continue
+		}
+
+		metricsChan <- prometheus.MustNewConstMetric(gaugeScheduleIntervalDesc,
+			prometheus.GaugeValue,
+			float64(t.IntervalMins*60), // Convert to seconds
+			t.GroupName,                // `group_name` label
+			t.Name,                     // `name` label
+			t.ProjectID)                // `project_id` label
+
+		c.logger.Infow("collected metric",
+			"group_name", t.GroupName,
+			"name", t.Name,
+			"project_id", t.ProjectID,
+			"metric", gaugeScheduleIntervalFQName)
+	}
+}
+
+func (c *Collector) collectInfo(transformations []*transformation.Transformation,
+	metricsChan chan<- prometheus.Metric,
+	waitGroup *sync.WaitGroup) {
+	defer waitGroup.Done()
+
+	// Create one gauge metric per transformation
+	for _, t := range transformations {
+		metricsChan <- prometheus.MustNewConstMetric(gaugeInfoDesc,
+			prometheus.GaugeValue,
+			metrics.EnumGaugeValuePresent.GaugeValue(),
+			t.GroupName,    // `group_name` label
+			t.GroupID,      // `group_id` label
+			t.Name,         // `name` label
+			t.ID,           // `id` label
+			t.ProjectID,    // `project_id` label
+			t.ScheduleType) // `schedule_type` label
+
+		c.logger.Infow("collected metric",
+			"group_name", t.GroupName,
+			"group_id", t.GroupID,
+			"name", t.Name,
+			"id", t.ID,
+			"project_id", t.ProjectID,
+			"schedule_type", t.ScheduleType,
+			"metric", gaugeInfoFQName)
+	}
+}
@@ -0,0 +1,8 @@
+package transformation
+
+import "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+
+var (
+	infoEnumGauge = metrics.NewEnumGauge(metrics.PresentMetricsGaugeValues,
+		"Information about a transformation")
+)
@@ -0,0 +1,8 @@
+package transformation
+
+import "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+
+var (
+	pausedEnumGauge = metrics.NewEnumGauge(metrics.BooleanMetricsGaugeValues,
+		"Current paused state of a transformation")
+)
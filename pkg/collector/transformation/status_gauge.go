@@ -0,0 +1,17 @@
+package transformation
+
+import "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+
+var (
+	statusGaugeValueSucceeded = metrics.NewEnumGaugeValue("succeeded", 0)
+	statusGaugeValueRunning   = metrics.NewEnumGaugeValue("running", 1)
+	statusGaugeValuePending   = metrics.NewEnumGaugeValue("pending", 2)
+	statusGaugeValueFailed    = metrics.NewEnumGaugeValue("failed", 3)
+
+	statusEnumGauge = metrics.NewEnumGauge([]*metrics.EnumGaugeValue{
+		statusGaugeValueSucceeded,
+		statusGaugeValueRunning,
+		statusGaugeValuePending,
+		statusGaugeValueFailed,
+	}, "Current status (result of the last run) of a transformation")
+)
@@ -0,0 +1,192 @@
+package usage
+
+import (
+	"sync"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/usage"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	namespace = "fivetran"
+	subsystem = "connector"
+
+	gaugeMonthlyActiveRowsName = "monthly_active_rows"
+	gaugeLastRefreshName       = "usage_last_refresh_timestamp_seconds"
+	counterErrorsTotalName     = "usage_errors_total"
+)
+
+var (
+	gaugeMonthlyActiveRowsFQName = prometheus.BuildFQName(namespace, subsystem, gaugeMonthlyActiveRowsName)
+	gaugeMonthlyActiveRowsDesc   = prometheus.NewDesc(
+		gaugeMonthlyActiveRowsFQName,
+		"Monthly active rows (MAR) of a connector for a month",
+		[]string{"group_name", "name", "month"},
+		prometheus.Labels{})
+	gaugeLastRefreshFQName = prometheus.BuildFQName(namespace, subsystem, gaugeLastRefreshName)
+	gaugeLastRefreshDesc   = prometheus.NewDesc(
+		gaugeLastRefreshFQName,
+		"Time of the last successful refresh of the cached usage of a group, in seconds since the epoch",
+		[]string{"group_name"},
+		prometheus.Labels{})
+)
+
+type Collector struct {
+	Listers []usage.Lister
+
+	lock               *sync.RWMutex
+	counterErrorsTotal *prometheus.CounterVec
+	// The failed background refreshes of each lister which have been counted in the
+	// error counter, so that each failure is counted once
+	countedRefreshFailures map[usage.Lister]uint64
+	logger                 *zap.SugaredLogger
+}
+
+func NewCollector(logger *zap.SugaredLogger, registerer prometheus.Registerer, listers []usage.Lister) *Collector {
+	logger = getComponentLogger(logger, "collector")
+
+	counterErrorsTotal := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      counterErrorsTotalName,
+		Help:      "Total errors encountered querying connector usage",
+	},
+		[]string{"group_name"})
+	registerer.MustRegister(counterErrorsTotal)
+
+	countedRefreshFailures := make(map[usage.Lister]uint64, len(listers))
+	for _, lister := range listers {
+		// Initialise the error counter to zero for all group names
+		counterErrorsTotal.WithLabelValues(lister.GetGroupName()).Add(0)
+		countedRefreshFailures[lister] = 0
+	}
+
+	return &Collector{
+		Listers:                listers,
+		lock:                   new(sync.RWMutex),
+		counterErrorsTotal:     counterErrorsTotal,
+		countedRefreshFailures: countedRefreshFailures,
+		logger:                 logger,
+	}
+}
+
+func (c *Collector) Describe(descsChan chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, descsChan)
+}
+
+func (c *Collector) Collect(metricsChan chan<- prometheus.Metric) {
+	c.lock.RLock()
+	listers := c.Listers
+	c.lock.RUnlock()
+
+	waitGroup := new(sync.WaitGroup)
+	waitGroup.Add(len(listers))
+	for _, lister := range listers {
+		go c.collectForLister(lister, metricsChan, waitGroup)
+	}
+	waitGroup.Wait()
+
+	for _, lister := range listers {
+		if reporter, ok := lister.(usage.RefreshReporter); ok {
+			c.collectLastRefresh(lister, reporter, metricsChan)
+		}
+	}
+}
+
+// SetListers atomically replaces the listers, e.g. when the config is reloaded.
+// The error counters of groups which are no longer listed are removed.
+func (c *Collector) SetListers(listers []usage.Lister) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	countedRefreshFailures := make(map[usage.Lister]uint64, len(listers))
+	groupNames := make(map[string]struct{}, len(listers))
+	for _, lister := range listers {
+		groupNames[lister.GetGroupName()] = struct{}{}
+		// Listers kept across the reload have already had their failures counted
+		countedRefreshFailures[lister] = c.countedRefreshFailures[lister]
+
+		// Initialise the error counter to zero for all group names
+		c.counterErrorsTotal.WithLabelValues(lister.GetGroupName()).Add(0)
+	}
+
+	for _, lister := range c.Listers {
+		if _, ok := groupNames[lister.GetGroupName()]; !ok {
+			c.counterErrorsTotal.DeleteLabelValues(lister.GetGroupName())
+		}
+	}
+
+	c.countedRefreshFailures = countedRefreshFailures
+	c.Listers = listers
+}
+
+// collectLastRefresh reports the outcome of the background refreshes of the lister,
+// counting any failed refreshes since the last scrape in the error counter
+func (c *Collector) collectLastRefresh(lister usage.Lister,
+	reporter usage.RefreshReporter,
+	metricsChan chan<- prometheus.Metric) {
+	lastSuccess, failures := reporter.LastRefresh()
+
+	c.lock.Lock()
+	counted, ok := c.countedRefreshFailures[lister]
+	if !ok {
+		// The lister has been replaced since the scrape began
+		c.lock.Unlock()
+		return
+	}
+	newFailures := failures - counted
+	c.countedRefreshFailures[lister] = failures
+	c.lock.Unlock()
+
+	if newFailures != 0 {
+		c.counterErrorsTotal.WithLabelValues(
+			lister.GetGroupName()).Add(float64(newFailures)) // `group_name` label
+	}
+
+	// The time is not known until the first successful refresh
+	if lastSuccess.IsZero() {
+		return
+	}
+
+	metricsChan <- prometheus.MustNewConstMetric(gaugeLastRefreshDesc,
+		prometheus.GaugeValue,
+		float64(lastSuccess.Unix()),
+		lister.GetGroupName()) // `group_name` label
+
+	c.logger.Infow("collected metric",
+		"group_name", lister.GetGroupName(),
+		"metric", gaugeLastRefreshFQName)
+}
+
+func (c *Collector) collectForLister(lister usage.Lister,
+	metricsChan chan<- prometheus.Metric,
+	waitGroup *sync.WaitGroup) {
+	defer waitGroup.Done()
+
+	usages, err := lister.List()
+	if err != nil {
+		// We do not have to send this metric on the metricsChan as it is already registered
+		// (it is a metric _belonging_ to this collector, rather than _collected_)
+		c.counterErrorsTotal.WithLabelValues(
+			lister.GetGroupName()).Inc() // `group_name` label
+		c.logger.Errorw("listing connector usage", "group_name", lister.GetGroupName(), "error", err)
+		return
+	}
+
+	// Create one gauge metric per connector per month
+	for _, u := range usages {
+		metricsChan <- prometheus.MustNewConstMetric(gaugeMonthlyActiveRowsDesc,
+			prometheus.GaugeValue,
+			float64(u.MonthlyActiveRows),
+			u.GroupName,     // `group_name` label
+			u.ConnectorName, // `name` label
+			u.Month)         // `month` label
+
+		c.logger.Infow("collected metric",
+			"group_name", u.GroupName,
+			"name", u.ConnectorName,
+			"month", u.Month,
+			"metric", gaugeMonthlyActiveRowsFQName)
+	}
+}
@@ -0,0 +1,266 @@
+package user
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/group"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+type collectFunc func(chan<- prometheus.Metric, *sync.WaitGroup)
+
+const (
+	namespace                = "fivetran"
+	subsystem                = "user"
+	teamMembershipsSubsystem = "group"
+
+	gaugeUsersName           = "users"
+	gaugeLastLoginName       = "last_login_timestamp_seconds"
+	gaugeTeamMembershipsName = "team_memberships"
+	counterErrorsTotalName   = "errors_total"
+)
+
+var (
+	gaugeUsersFQName = prometheus.BuildFQName(namespace, "", gaugeUsersName)
+	gaugeUsersDesc   = prometheus.NewDesc(
+		gaugeUsersFQName,
+		"Number of users of the account",
+		[]string{"role", "active", "verified"},
+		prometheus.Labels{})
+	// Users are identified by ID only, as their email is personal data, which must not
+	// end up in the TSDB or any remote-write target
+	gaugeLastLoginFQName = prometheus.BuildFQName(namespace, subsystem, gaugeLastLoginName)
+	gaugeLastLoginDesc   = prometheus.NewDesc(
+		gaugeLastLoginFQName,
+		"Time a user last logged in, in seconds since the epoch",
+		[]string{"id"},
+		prometheus.Labels{})
+	gaugeTeamMembershipsFQName = prometheus.BuildFQName(namespace, teamMembershipsSubsystem, gaugeTeamMembershipsName)
+	gaugeTeamMembershipsDesc   = prometheus.NewDesc(
+		gaugeTeamMembershipsFQName,
+		"Number of teams which are members of a group",
+		[]string{"group_name", "role"},
+		prometheus.Labels{})
+)
+
+// userCountKey is the combination of labels users are counted by
+type userCountKey struct {
+	role     string
+	active   bool
+	verified bool
+}
+
+type teamMembershipCountKey struct {
+	groupName string
+	role      string
+}
+
+type Collector struct {
+	Lister     user.Lister
+	TeamLister user.TeamLister
+	Groups     []*group.Group
+
+	lock               *sync.RWMutex
+	counterErrorsTotal *prometheus.CounterVec
+	// The failed background refreshes of the team lister which have been counted in
+	// the error counter, so that each failure is counted once
+	countedTeamRefreshFailures uint64
+	collectFuncs               []collectFunc
+	logger                     *zap.SugaredLogger
+}
+
+func NewCollector(logger *zap.SugaredLogger,
+	registerer prometheus.Registerer,
+	lister user.Lister,
+	teamLister user.TeamLister,
+	groups []*group.Group) *Collector {
+	logger = getComponentLogger(logger, "collector")
+
+	counterErrorsTotal := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      counterErrorsTotalName,
+		Help:      "Total errors encountered querying users and teams",
+	},
+		[]string{"resource"})
+	registerer.MustRegister(counterErrorsTotal)
+
+	// Initialise the error counter to zero for all resources
+	counterErrorsTotal.WithLabelValues("users").Add(0)
+	counterErrorsTotal.WithLabelValues("teams").Add(0)
+
+	collector := &Collector{
+		Lister:             lister,
+		TeamLister:         teamLister,
+		Groups:             groups,
+		lock:               new(sync.RWMutex),
+		counterErrorsTotal: counterErrorsTotal,
+		logger:             logger,
+	}
+
+	collectFuncs := []collectFunc{
+		collector.collectUsers,
+		collector.collectTeamMemberships,
+	}
+	collector.collectFuncs = collectFuncs
+
+	return collector
+}
+
+func (c *Collector) Describe(descsChan chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, descsChan)
+}
+
+func (c *Collector) Collect(metricsChan chan<- prometheus.Metric) {
+	waitGroup := new(sync.WaitGroup)
+	waitGroup.Add(len(c.collectFuncs))
+	for _, collectFunc := range c.collectFuncs {
+		go collectFunc(metricsChan, waitGroup)
+	}
+	waitGroup.Wait()
+}
+
+// SetSources atomically replaces the listers and collected groups, e.g. when the
+// config is reloaded
+func (c *Collector) SetSources(lister user.Lister, teamLister user.TeamLister, groups []*group.Group) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// A team lister kept across the reload has already had its failures counted
+	if teamLister != c.TeamLister {
+		c.countedTeamRefreshFailures = 0
+	}
+
+	c.Lister = lister
+	c.TeamLister = teamLister
+	c.Groups = groups
+}
+
+func (c *Collector) collectUsers(metricsChan chan<- prometheus.Metric, waitGroup *sync.WaitGroup) {
+	defer waitGroup.Done()
+
+	c.lock.RLock()
+	lister := c.Lister
+	c.lock.RUnlock()
+
+	users, err := lister.List()
+	if err != nil {
+		// We do not have to send this metric on the metricsChan as it is already registered
+		// (it is a metric _belonging_ to this collector, rather than _collected_)
+		c.counterErrorsTotal.WithLabelValues("users").Inc() // `resource` label
+		c.logger.Errorw("listing users", "error", err)
+		return
+	}
+
+	counts := make(map[userCountKey]int)
+	for _, u := range users {
+		counts[userCountKey{role: u.Role, active: u.Active, verified: u.Verified}]++
+
+		// Users who have never logged in have no last login time to report
+		if u.LastLogin == nil {
+			continue
+		}
+
+		metricsChan <- prometheus.MustNewConstMetric(gaugeLastLoginDesc,
+			prometheus.GaugeValue,
+			float64(u.LastLogin.Unix()),
+			u.ID) // `id` label
+
+		c.logger.Infow("collected metric",
+			"id", u.ID,
+			"metric", gaugeLastLoginFQName)
+	}
+
+	// Create one gauge metric per combination of role, active and verified
+	for key, count := range counts {
+		metricsChan <- prometheus.MustNewConstMetric(gaugeUsersDesc,
+			prometheus.GaugeValue,
+			float64(count),
+			key.role,                         // `role` label
+			strconv.FormatBool(key.active),   // `active` label
+			strconv.FormatBool(key.verified)) // `verified` label
+
+		c.logger.Infow("collected metric",
+			"role", key.role,
+			"active", key.active,
+			"verified", key.verified,
+			"metric", gaugeUsersFQName)
+	}
+}
+
+// countTeamRefreshFailures counts any failed background refreshes of the team lister
+// since the last scrape in the error counter
+func (c *Collector) countTeamRefreshFailures(teamLister user.TeamLister, reporter user.RefreshReporter) {
+	_, failures := reporter.LastRefresh()
+
+	c.lock.Lock()
+	if teamLister != c.TeamLister {
+		// The team lister has been replaced since the scrape began
+		c.lock.Unlock()
+		return
+	}
+	newFailures := failures - c.countedTeamRefreshFailures
+	c.countedTeamRefreshFailures = failures
+	c.lock.Unlock()
+
+	if newFailures != 0 {
+		c.counterErrorsTotal.WithLabelValues("teams").Add(float64(newFailures)) // `resource` label
+	}
+}
+
+func (c *Collector) collectTeamMemberships(metricsChan chan<- prometheus.Metric, waitGroup *sync.WaitGroup) {
+	defer waitGroup.Done()
+
+	c.lock.RLock()
+	teamLister := c.TeamLister
+	groups := c.Groups
+	c.lock.RUnlock()
+
+	teams, err := teamLister.List()
+	if err != nil {
+		// We do not have to send this metric on the metricsChan as it is already registered
+		// (it is a metric _belonging_ to this collector, rather than _collected_)
+		c.counterErrorsTotal.WithLabelValues("teams").Inc() // `resource` label
+		c.logger.Errorw("listing teams", "error", err)
+		return
+	}
+
+	if reporter, ok := teamLister.(user.RefreshReporter); ok {
+		c.countTeamRefreshFailures(teamLister, reporter)
+	}
+
+	groupNames := make(map[string]string, len(groups)) // Keyed by group ID
+	for _, g := range groups {
+		groupNames[g.ID] = g.Name
+	}
+
+	// Only memberships of the collected groups are counted
+	counts := make(map[teamMembershipCountKey]int)
+	for _, team := range teams {
+		for _, membership := range team.GroupMemberships {
+			groupName, ok := groupNames[membership.GroupID]
+			if !ok {
+				continue
+			}
+
+			counts[teamMembershipCountKey{groupName: groupName, role: membership.Role}]++
+		}
+	}
+
+	// Create one gauge metric per group and role
+	for key, count := range counts {
+		metricsChan <- prometheus.MustNewConstMetric(gaugeTeamMembershipsDesc,
+			prometheus.GaugeValue,
+			float64(count),
+			key.groupName, // `group_name` label
+			key.role)      // `role` label
+
+		c.logger.Infow("collected metric",
+			"group_name", key.groupName,
+			"role", key.role,
+			"metric", gaugeTeamMembershipsFQName)
+	}
+}
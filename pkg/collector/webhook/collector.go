@@ -0,0 +1,185 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/connector"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/webhook"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	namespace = "fivetran"
+	subsystem = "connector"
+
+	counterSyncsTotalName         = "syncs_total"
+	histogramSyncDurationName     = "sync_duration_seconds"
+	counterWebhookErrorsTotalName = "webhook_errors_total"
+
+	// The start of a sync is forgotten if the end of the sync is not received within
+	// this time, so that starts whose end is lost (or is for a connector which is not
+	// collected) do not accumulate. This is well beyond the longest expected sync.
+	syncStartExpiry = 7 * 24 * time.Hour
+)
+
+var (
+	// Syncs range from seconds to many hours (e.g. historical syncs)
+	syncDurationBuckets = []float64{10, 30, 60, 120, 300, 600, 1200, 1800, 3600, 7200, 14400, 28800, 86400}
+)
+
+// Collector records connector syncs from webhook events. Unlike the other collectors,
+// nothing is queried at scrape time; the metrics are updated as the events arrive.
+// Events of all accounts are delivered to the one collector, so it is not registered
+// per account, and instead labels the metrics of each connector with its account.
+type Collector struct {
+	Resolver connector.Resolver
+
+	counterSyncsTotal         *prometheus.CounterVec
+	histogramSyncDuration     *prometheus.HistogramVec
+	counterWebhookErrorsTotal prometheus.Counter
+	lock                      *sync.Mutex
+	syncStartTimes            map[string]time.Time // Keyed by connector ID
+	logger                    *zap.SugaredLogger
+}
+
+func NewCollector(logger *zap.SugaredLogger, resolver connector.Resolver) *Collector {
+	logger = getComponentLogger(logger, "collector")
+
+	counterSyncsTotal := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      counterSyncsTotalName,
+		Help:      "Total syncs of a connector completed, as reported by webhook events",
+	},
+		[]string{"account", "group_name", "name", "status"})
+
+	histogramSyncDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      histogramSyncDurationName,
+		Help:      "Duration of completed syncs of a connector, as reported by webhook events",
+		Buckets:   syncDurationBuckets,
+	},
+		[]string{"account", "group_name", "name"})
+
+	counterWebhookErrorsTotal := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      counterWebhookErrorsTotalName,
+		Help:      "Total errors encountered handling webhook events",
+	})
+
+	return &Collector{
+		Resolver:                  resolver,
+		counterSyncsTotal:         counterSyncsTotal,
+		histogramSyncDuration:     histogramSyncDuration,
+		counterWebhookErrorsTotal: counterWebhookErrorsTotal,
+		lock:                      new(sync.Mutex),
+		syncStartTimes:            make(map[string]time.Time),
+		logger:                    logger,
+	}
+}
+
+func (c *Collector) Describe(descsChan chan<- *prometheus.Desc) {
+	c.counterSyncsTotal.Describe(descsChan)
+	c.histogramSyncDuration.Describe(descsChan)
+	c.counterWebhookErrorsTotal.Describe(descsChan)
+}
+
+func (c *Collector) Collect(metricsChan chan<- prometheus.Metric) {
+	c.counterSyncsTotal.Collect(metricsChan)
+	c.histogramSyncDuration.Collect(metricsChan)
+	c.counterWebhookErrorsTotal.Collect(metricsChan)
+}
+
+func (c *Collector) HandleEvent(event *webhook.Event) error {
+	var err error
+	switch event.Event {
+	case webhook.EventTypeSyncStart:
+		err = c.handleSyncStart(event)
+	case webhook.EventTypeSyncEnd:
+		err = c.handleSyncEnd(event)
+	default:
+		c.logger.Infow("ignoring webhook event", "event", event.Event, "connector_id", event.ConnectorID)
+	}
+
+	if err != nil {
+		c.counterWebhookErrorsTotal.Inc()
+		return err
+	}
+
+	return nil
+}
+
+func (c *Collector) handleSyncStart(event *webhook.Event) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for connectorID, startTime := range c.syncStartTimes {
+		if time.Since(startTime) > syncStartExpiry {
+			c.logger.Warnw("expiring sync start without sync end",
+				"connector_id", connectorID,
+				"start_time", startTime)
+			delete(c.syncStartTimes, connectorID)
+		}
+	}
+
+	c.syncStartTimes[event.ConnectorID] = event.Created
+	return nil
+}
+
+func (c *Collector) handleSyncEnd(event *webhook.Event) error {
+	data := new(webhook.SyncEndData)
+	if err := json.Unmarshal(event.Data, data); err != nil {
+		c.logger.Errorw("unmarshalling sync end data", "connector_id", event.ConnectorID, "error", err)
+		return fmt.Errorf("unmarshalling sync end data: %w", err)
+	}
+
+	account, conn, err := c.Resolver.ResolveIDToConnector(event.ConnectorID)
+	if err != nil {
+		c.logger.Errorw("resolving connector ID", "connector_id", event.ConnectorID, "error", err)
+		return fmt.Errorf("resolving connector ID %q: %w", event.ConnectorID, err)
+	}
+
+	status := strings.ToLower(data.Status)
+	c.counterSyncsTotal.WithLabelValues(
+		account,        // `account` label
+		conn.GroupName, // `group_name` label
+		conn.Name,      // `name` label
+		status).Inc()   // `status` label
+
+	c.lock.Lock()
+	startTime, started := c.syncStartTimes[event.ConnectorID]
+	delete(c.syncStartTimes, event.ConnectorID)
+	c.lock.Unlock()
+
+	// The start of the sync may have been missed, e.g. if the exporter was restarted mid-sync
+	if !started {
+		c.logger.Warnw("sync end without sync start",
+			"account", account,
+			"group_name", conn.GroupName,
+			"name", conn.Name,
+			"status", status)
+		return nil
+	}
+
+	duration := event.Created.Sub(startTime)
+	c.histogramSyncDuration.WithLabelValues(
+		account,        // `account` label
+		conn.GroupName, // `group_name` label
+		conn.Name,      // `name` label
+	).Observe(duration.Seconds())
+
+	c.logger.Infow("recorded sync",
+		"account", account,
+		"group_name", conn.GroupName,
+		"name", conn.Name,
+		"status", status,
+		"duration", duration)
+	return nil
+}
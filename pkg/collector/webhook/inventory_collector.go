@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/group"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/webhook"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	inventorySubsystem = "webhook"
+
+	gaugeActiveName                 = "active"
+	gaugeInfoName                   = "info"
+	counterInventoryErrorsTotalName = "inventory_errors_total"
+)
+
+var (
+	gaugeActiveFQName = prometheus.BuildFQName(namespace, inventorySubsystem, gaugeActiveName)
+	gaugeActiveDesc   = prometheus.NewDesc(
+		gaugeActiveFQName,
+		activeEnumGauge.Describe(),
+		[]string{"group_name", "id"},
+		prometheus.Labels{})
+	gaugeInfoFQName = prometheus.BuildFQName(namespace, inventorySubsystem, gaugeInfoName)
+	gaugeInfoDesc   = prometheus.NewDesc(
+		gaugeInfoFQName,
+		infoEnumGauge.Describe(),
+		[]string{"group_name", "id", "url", "events"},
+		prometheus.Labels{})
+)
+
+// InventoryCollector reports the webhooks configured in Fivetran, whether or not they
+// deliver events to the exporter. Only account webhooks and the webhooks of the collected
+// groups are reported; account webhooks have an empty group name.
+type InventoryCollector struct {
+	Lister webhook.Lister
+	Groups []*group.Group
+
+	lock                        *sync.RWMutex
+	counterInventoryErrorsTotal prometheus.Counter
+	logger                      *zap.SugaredLogger
+}
+
+func NewInventoryCollector(logger *zap.SugaredLogger,
+	registerer prometheus.Registerer,
+	lister webhook.Lister,
+	groups []*group.Group) *InventoryCollector {
+	logger = getComponentLogger(logger, "inventory-collector")
+
+	counterInventoryErrorsTotal := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: inventorySubsystem,
+		Name:      counterInventoryErrorsTotalName,
+		Help:      "Total errors encountered listing webhooks",
+	})
+	registerer.MustRegister(counterInventoryErrorsTotal)
+
+	return &InventoryCollector{
+		Lister:                      lister,
+		Groups:                      groups,
+		lock:                        new(sync.RWMutex),
+		counterInventoryErrorsTotal: counterInventoryErrorsTotal,
+		logger:                      logger,
+	}
+}
+
+func (c *InventoryCollector) Describe(descsChan chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, descsChan)
+}
+
+// SetSources atomically replaces the lister and collected groups, e.g. when the
+// config is reloaded
+func (c *InventoryCollector) SetSources(lister webhook.Lister, groups []*group.Group) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.Lister = lister
+	c.Groups = groups
+}
+
+func (c *InventoryCollector) Collect(metricsChan chan<- prometheus.Metric) {
+	c.lock.RLock()
+	lister := c.Lister
+	groups := c.Groups
+	c.lock.RUnlock()
+
+	webhooks, err := lister.List()
+	if err != nil {
+		// We do not have to send this metric on the metricsChan as it is already registered
+		// (it is a metric _belonging_ to this collector, rather than _collected_)
+		c.counterInventoryErrorsTotal.Inc()
+		c.logger.Errorw("listing webhooks", "error", err)
+		return
+	}
+
+	groupNames := make(map[string]string, len(groups)+1) // Keyed by group ID
+	groupNames[""] = ""                                  // Account webhooks
+	for _, g := range groups {
+		groupNames[g.ID] = g.Name
+	}
+
+	// Create one gauge metric of each type per webhook
+	for _, w := range webhooks {
+		groupName, ok := groupNames[w.GroupID]
+		if !ok {
+			continue
+		}
+
+		value := metrics.EnumGaugeValueFalse
+		if w.Active {
+			value = metrics.EnumGaugeValueTrue
+		}
+
+		metricsChan <- prometheus.MustNewConstMetric(gaugeActiveDesc,
+			prometheus.GaugeValue,
+			value.GaugeValue(),
+			groupName, // `group_name` label
+			w.ID)      // `id` label
+
+		c.logger.Infow("collected metric",
+			"group_name", groupName,
+			"id", w.ID,
+			"metric", gaugeActiveFQName)
+
+		events := strings.Join(w.Events, ",")
+		metricsChan <- prometheus.MustNewConstMetric(gaugeInfoDesc,
+			prometheus.GaugeValue,
+			metrics.EnumGaugeValuePresent.GaugeValue(),
+			groupName, // `group_name` label
+			w.ID,      // `id` label
+			w.URL,     // `url` label
+			events)    // `events` label
+
+		c.logger.Infow("collected metric",
+			"group_name", groupName,
+			"id", w.ID,
+			"url", w.URL,
+			"events", events,
+			"metric", gaugeInfoFQName)
+	}
+}
@@ -0,0 +1,10 @@
+package webhook
+
+import "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+
+var (
+	activeEnumGauge = metrics.NewEnumGauge(metrics.BooleanMetricsGaugeValues,
+		"Whether or not a webhook is active")
+	infoEnumGauge = metrics.NewEnumGauge(metrics.PresentMetricsGaugeValues,
+		"Information about a webhook")
+)
@@ -0,0 +1,8 @@
+package webhook
+
+import "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+
+var (
+	registeredEnumGauge = metrics.NewEnumGauge(metrics.BooleanMetricsGaugeValues,
+		"Whether or not a webhook delivering events to the exporter is registered for a group")
+)
@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"sync"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/collector/metrics"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/webhook"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	registrationSubsystem = "webhook"
+
+	gaugeRegisteredName = "registered"
+)
+
+var (
+	gaugeRegisteredFQName = prometheus.BuildFQName(namespace, registrationSubsystem, gaugeRegisteredName)
+	gaugeRegisteredDesc   = prometheus.NewDesc(
+		gaugeRegisteredFQName,
+		registeredEnumGauge.Describe(),
+		[]string{"group_name"},
+		prometheus.Labels{})
+)
+
+// RegistrationCollector reports whether the group webhooks delivering events to
+// the receiver are registered, as last determined by the reconciler
+type RegistrationCollector struct {
+	Reporter webhook.RegistrationReporter
+
+	lock   *sync.RWMutex
+	logger *zap.SugaredLogger
+}
+
+func NewRegistrationCollector(logger *zap.SugaredLogger, reporter webhook.RegistrationReporter) *RegistrationCollector {
+	logger = getComponentLogger(logger, "registration-collector")
+
+	return &RegistrationCollector{
+		Reporter: reporter,
+		lock:     new(sync.RWMutex),
+		logger:   logger,
+	}
+}
+
+func (c *RegistrationCollector) Describe(descsChan chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, descsChan)
+}
+
+// SetReporter atomically replaces the reporter, e.g. when the config is reloaded
+func (c *RegistrationCollector) SetReporter(reporter webhook.RegistrationReporter) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.Reporter = reporter
+}
+
+func (c *RegistrationCollector) Collect(metricsChan chan<- prometheus.Metric) {
+	c.lock.RLock()
+	reporter := c.Reporter
+	c.lock.RUnlock()
+
+	// Create one gauge metric per group
+	for groupName, registered := range reporter.Registered() {
+		value := metrics.EnumGaugeValueFalse
+		if registered {
+			value = metrics.EnumGaugeValueTrue
+		}
+
+		metricsChan <- prometheus.MustNewConstMetric(gaugeRegisteredDesc,
+			prometheus.GaugeValue,
+			value.GaugeValue(),
+			groupName) // `group_name` label
+
+		c.logger.Infow("collected metric",
+			"group_name", groupName,
+			"metric", gaugeRegisteredFQName)
+	}
+}
@@ -0,0 +1,269 @@
+package compliance
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/connector"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/schema"
+	"go.uber.org/zap"
+)
+
+// ActualState is the state a column (or table) was found to be in
+type ActualState string
+
+const (
+	ActualStateSynced   ActualState = "synced"
+	ActualStateHashed   ActualState = "hashed"
+	ActualStateBlocked  ActualState = "blocked"
+	ActualStateExcluded ActualState = "excluded"
+)
+
+func (as ActualState) satisfies(required RequiredState) bool {
+	switch required {
+	case RequiredStateHashed:
+		return as != ActualStateSynced
+	case RequiredStateBlocked:
+		return as == ActualStateBlocked || as == ActualStateExcluded
+	case RequiredStateExcluded:
+		return as == ActualStateExcluded
+	default:
+		return false
+	}
+}
+
+type Violation struct {
+	GroupName     string        `json:"group_name"`
+	ConnectorName string        `json:"connector_name"`
+	ConnectorID   string        `json:"connector_id"`
+	Rule          string        `json:"rule"`
+	Schema        string        `json:"schema"`
+	Table         string        `json:"table"`
+	Column        string        `json:"column,omitempty"`
+	Required      RequiredState `json:"required_state"`
+	Actual        ActualState   `json:"actual_state"`
+}
+
+// ConnectorReport is the outcome of checking a single connector against
+// all of the rules applicable to it
+type ConnectorReport struct {
+	ID         string
+	Name       string
+	GroupName  string
+	Rules      []*Rule
+	Violations []*Violation
+}
+
+func (r *ConnectorReport) ViolationCount(rule *Rule) int {
+	count := 0
+	for _, violation := range r.Violations {
+		if violation.Rule == rule.Name {
+			count++
+		}
+	}
+
+	return count
+}
+
+type Checker interface {
+	Check(lister connector.Lister) ([]*ConnectorReport, error)
+}
+
+type SchemaChecker struct {
+	Rules           []*Rule
+	SchemaDescriber schema.Describer
+	logger          *zap.SugaredLogger
+}
+
+func NewSchemaChecker(logger *zap.SugaredLogger,
+	rules []*Rule,
+	schemaDescriber schema.Describer) *SchemaChecker {
+	logger = getComponentLogger(logger, "schema-checker")
+
+	return &SchemaChecker{
+		Rules:           rules,
+		SchemaDescriber: schemaDescriber,
+		logger:          logger,
+	}
+}
+
+// Check lists the connectors of the lister's group and checks the schema config of each
+// against the applicable rules. Connectors to which no rules apply are not described.
+func (c *SchemaChecker) Check(lister connector.Lister) ([]*ConnectorReport, error) {
+	connectors, err := lister.List()
+	if err != nil {
+		c.logger.Errorw("listing connectors", "group_name", lister.GetGroupName(), "error", err)
+		return nil, fmt.Errorf("listing connectors: %w", err)
+	}
+
+	reports := make([]*ConnectorReport, 0, len(connectors))
+	for _, conn := range connectors {
+		rules := c.rulesForConnector(conn)
+		if len(rules) == 0 {
+			continue
+		}
+
+		reports = append(reports, &ConnectorReport{
+			ID:        conn.ID,
+			Name:      conn.Name,
+			GroupName: conn.GroupName,
+			Rules:     rules,
+		})
+	}
+
+	waitGroup := new(sync.WaitGroup)
+	waitGroup.Add(len(reports))
+	errs := make([]error, len(reports))
+	for i, report := range reports {
+		go func(i int, report *ConnectorReport) {
+			defer waitGroup.Done()
+			errs[i] = c.checkConnector(report)
+		}(i, report)
+	}
+	waitGroup.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.logger.Infow("checked connectors for compliance",
+		"group_name", lister.GetGroupName(),
+		"count", len(reports))
+	return reports, nil
+}
+
+func (c *SchemaChecker) rulesForConnector(conn *connector.Connector) []*Rule {
+	rules := make([]*Rule, 0, len(c.Rules))
+	for _, rule := range c.Rules {
+		if rule.MatchesConnector(conn.GroupName, conn.Name) {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+func (c *SchemaChecker) checkConnector(report *ConnectorReport) error {
+	config, err := c.SchemaDescriber.Describe(report.ID)
+	if err != nil {
+		c.logger.Errorw("describing schema config",
+			"group_name", report.GroupName,
+			"name", report.Name,
+			"id", report.ID,
+			"error", err)
+		return fmt.Errorf("describing schema config for connector %q: %w", report.Name, err)
+	}
+
+	for _, s := range config.Schemas {
+		for _, table := range s.Tables {
+			tableState := ActualStateSynced
+			if !s.Enabled || !table.Enabled {
+				tableState = ActualStateExcluded
+			}
+
+			// Described on first use, as only tables matching a column rule need them
+			var columns []*schema.Column
+
+			for _, rule := range report.Rules {
+				if !rule.MatchesTable(s.Name, table.Name) {
+					continue
+				}
+
+				if rule.IsTableRule() {
+					if !tableState.satisfies(rule.Required) {
+						report.Violations = append(report.Violations,
+							newViolation(report, rule, s, table, nil, tableState))
+					}
+					continue
+				}
+
+				// Every column of an excluded table complies with every rule
+				if tableState == ActualStateExcluded {
+					continue
+				}
+
+				// The schema config only includes the columns which have been explicitly
+				// configured, so all columns of the table are described
+				if columns == nil {
+					columns, err = c.SchemaDescriber.DescribeColumns(report.ID, s.Name, table.Name)
+					if err != nil {
+						c.logger.Errorw("describing column config",
+							"group_name", report.GroupName,
+							"name", report.Name,
+							"id", report.ID,
+							"schema", s.Name,
+							"table", table.Name,
+							"error", err)
+						return fmt.Errorf("describing column config for table \"%s.%s\" of connector %q: %w",
+							s.Name, table.Name, report.Name, err)
+					}
+				}
+
+				for _, column := range columns {
+					if !rule.MatchesColumn(column.Name) {
+						continue
+					}
+
+					columnState := columnState(tableState, column)
+					if !columnState.satisfies(rule.Required) {
+						report.Violations = append(report.Violations,
+							newViolation(report, rule, s, table, column, columnState))
+					}
+				}
+			}
+		}
+	}
+
+	for _, violation := range report.Violations {
+		c.logger.Warnw("found compliance violation",
+			"group_name", violation.GroupName,
+			"name", violation.ConnectorName,
+			"rule", violation.Rule,
+			"schema", violation.Schema,
+			"table", violation.Table,
+			"column", violation.Column,
+			"required_state", violation.Required,
+			"actual_state", violation.Actual)
+	}
+
+	return nil
+}
+
+func columnState(tableState ActualState, column *schema.Column) ActualState {
+	switch {
+	case tableState == ActualStateExcluded:
+		return ActualStateExcluded
+	case !column.Enabled:
+		return ActualStateBlocked
+	case column.Hashed:
+		return ActualStateHashed
+	default:
+		return ActualStateSynced
+	}
+}
+
+func newViolation(report *ConnectorReport,
+	rule *Rule,
+	s *schema.Schema,
+	table *schema.Table,
+	column *schema.Column,
+	actual ActualState) *Violation {
+	violation := &Violation{
+		GroupName:     report.GroupName,
+		ConnectorName: report.Name,
+		ConnectorID:   report.ID,
+		Rule:          rule.Name,
+		Schema:        s.Name,
+		Table:         table.Name,
+		Required:      rule.Required,
+		Actual:        actual,
+	}
+
+	if column != nil {
+		violation.Column = column.Name
+	}
+
+	return violation
+}
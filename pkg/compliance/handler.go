@@ -0,0 +1,123 @@
+package compliance
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/connector"
+	"go.uber.org/zap"
+)
+
+type handlerResp struct {
+	Violations []*handlerRespViolation `json:"violations"`
+	Errors     []*handlerRespError     `json:"errors"`
+}
+
+type handlerRespViolation struct {
+	Account string `json:"account"`
+	*Violation
+}
+
+type handlerRespError struct {
+	Account   string `json:"account"`
+	GroupName string `json:"group_name"`
+	Error     string `json:"error"`
+}
+
+// HandlerSource is the checker and connector listers of a single account
+type HandlerSource struct {
+	Account string
+	Checker Checker
+	Listers []connector.Lister
+}
+
+// Handler serves a JSON report of all columns violating the compliance rules,
+// across all accounts
+type Handler struct {
+	Sources []*HandlerSource
+
+	lock   *sync.RWMutex
+	logger *zap.SugaredLogger
+}
+
+func NewHandler(logger *zap.SugaredLogger, sources []*HandlerSource) *Handler {
+	logger = getComponentLogger(logger, "handler")
+
+	return &Handler{
+		Sources: sources,
+		lock:    new(sync.RWMutex),
+		logger:  logger,
+	}
+}
+
+// SetSources atomically replaces the sources, e.g. when the config is reloaded
+func (h *Handler) SetSources(sources []*HandlerSource) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.Sources = sources
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp := &handlerResp{
+		Violations: make([]*handlerRespViolation, 0),
+		Errors:     make([]*handlerRespError, 0),
+	}
+
+	h.lock.RLock()
+	sources := h.Sources
+	h.lock.RUnlock()
+
+	lock := new(sync.Mutex)
+	waitGroup := new(sync.WaitGroup)
+	for _, source := range sources {
+		waitGroup.Add(len(source.Listers))
+		for _, lister := range source.Listers {
+			go func(source *HandlerSource, lister connector.Lister) {
+				defer waitGroup.Done()
+
+				reports, err := source.Checker.Check(lister)
+
+				lock.Lock()
+				defer lock.Unlock()
+				if err != nil {
+					resp.Errors = append(resp.Errors, &handlerRespError{
+						Account:   source.Account,
+						GroupName: lister.GetGroupName(),
+						Error:     err.Error(),
+					})
+					return
+				}
+
+				for _, report := range reports {
+					for _, violation := range report.Violations {
+						resp.Violations = append(resp.Violations, &handlerRespViolation{
+							Account:   source.Account,
+							Violation: violation,
+						})
+					}
+				}
+			}(source, lister)
+		}
+	}
+	waitGroup.Wait()
+
+	status := http.StatusOK
+	if len(resp.Errors) > 0 {
+		// The report is incomplete, so cannot be used to prove compliance
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Errorw("encoding compliance report", "error", err)
+	}
+}
@@ -0,0 +1,165 @@
+package compliance
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+The state a column is required to be in. The available values are:
+hashed - the column must not be synced in plain text (hashed, blocked or excluded all comply);
+blocked - the column must not be synced at all (blocked or excluded comply);
+excluded - the table containing the column must not be synced at all.
+*/
+type RequiredState string
+
+const (
+	RequiredStateHashed   RequiredState = "hashed"
+	RequiredStateBlocked  RequiredState = "blocked"
+	RequiredStateExcluded RequiredState = "excluded"
+)
+
+func NewRequiredState(str string) (RequiredState, error) {
+	switch str {
+	case string(RequiredStateHashed):
+		return RequiredStateHashed, nil
+	case string(RequiredStateBlocked):
+		return RequiredStateBlocked, nil
+	case string(RequiredStateExcluded):
+		return RequiredStateExcluded, nil
+	default:
+		return RequiredState(""), fmt.Errorf("illegal RequiredState: %q", str)
+	}
+}
+
+func (rs *RequiredState) UnmarshalYAML(value *yaml.Node) error {
+	var str string
+	if err := value.Decode(&str); err != nil {
+		return fmt.Errorf("unmarshalling RequiredState: %w", err)
+	}
+
+	requiredState, err := NewRequiredState(str)
+	if err != nil {
+		return fmt.Errorf("constructing RequiredState: %w", err)
+	}
+
+	*rs = requiredState
+	return nil
+}
+
+// Rule requires that columns matching the patterns are in the required state.
+// Patterns use path.Match syntax, and an empty pattern matches everything.
+// The table pattern is matched against "<schema>.<table>".
+// If the column pattern is empty, the rule applies to whole tables, and only
+// the excluded state may be required.
+type Rule struct {
+	Name      string        `yaml:"name"`
+	Group     string        `yaml:"group"`
+	Connector string        `yaml:"connector"`
+	Table     string        `yaml:"table"`
+	Column    string        `yaml:"column"`
+	Required  RequiredState `yaml:"required"`
+}
+
+func (r *Rule) MatchesConnector(groupName, connectorName string) bool {
+	return matches(r.Group, groupName) && matches(r.Connector, connectorName)
+}
+
+func (r *Rule) MatchesTable(schemaName, tableName string) bool {
+	return matches(r.Table, schemaName+"."+tableName)
+}
+
+func (r *Rule) MatchesColumn(columnName string) bool {
+	return matches(r.Column, columnName)
+}
+
+func (r *Rule) IsTableRule() bool {
+	return r.Column == ""
+}
+
+func (r *Rule) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("rule name not set")
+	}
+
+	if r.Required == "" {
+		return fmt.Errorf("required state not set for rule %q", r.Name)
+	}
+
+	if r.IsTableRule() && r.Required != RequiredStateExcluded {
+		return fmt.Errorf("rule %q without column pattern must require %q", r.Name, RequiredStateExcluded)
+	}
+
+	for _, pattern := range []string{r.Group, r.Connector, r.Table, r.Column} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q in rule %q: %w", pattern, r.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// The patterns have already been validated, so the error can safely be ignored
+func matches(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
+
+type rulesFile struct {
+	Rules []*Rule `yaml:"rules"`
+}
+
+// LoadRulesFile reads and validates compliance rules from a YAML (or JSON) file
+// of the form:
+//
+//	rules:
+//	  - name: customer-email
+//	    group: production
+//	    connector: "*_postgres"
+//	    table: public.customers
+//	    column: email
+//	    required: hashed
+func LoadRulesFile(logger *zap.SugaredLogger, filename string) ([]*Rule, error) {
+	logger = getComponentLogger(logger, "rules-loader")
+
+	file, err := os.Open(filename)
+	if err != nil {
+		logger.Errorw("opening rules file", "filename", filename, "error", err)
+		return nil, fmt.Errorf("opening rules file %q: %w", filename, err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+
+	rulesFile := new(rulesFile)
+	if err := decoder.Decode(rulesFile); err != nil {
+		logger.Errorw("decoding rules file", "filename", filename, "error", err)
+		return nil, fmt.Errorf("decoding rules file %q: %w", filename, err)
+	}
+
+	names := make(map[string]struct{}, len(rulesFile.Rules))
+	for _, rule := range rulesFile.Rules {
+		if err := rule.validate(); err != nil {
+			logger.Errorw("validating rule", "filename", filename, "error", err)
+			return nil, fmt.Errorf("validating rule in file %q: %w", filename, err)
+		}
+
+		if _, exists := names[rule.Name]; exists {
+			logger.Errorw("duplicate rule name", "filename", filename, "rule", rule.Name)
+			return nil, fmt.Errorf("duplicate rule name %q in file %q", rule.Name, filename)
+		}
+		names[rule.Name] = struct{}{}
+	}
+
+	logger.Infow("loaded compliance rules", "filename", filename, "count", len(rulesFile.Rules))
+	return rulesFile.Rules, nil
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultAPICallTimeout      = 10 * time.Second
+	DefaultMetricsPort         = 9799
+	DefaultTelemetryPath       = "/metrics"
+	DefaultReadinessWindow     = 5 * time.Minute
+	DefaultShutdownGracePeriod = 25 * time.Second // Within the default Kubernetes termination grace period
+)
+
+// DefaultSourcer sources the defaults of the settings which have sensible defaults.
+// All other settings are reported as not set.
+type DefaultSourcer struct{}
+
+func NewDefaultSourcer() *DefaultSourcer {
+	return new(DefaultSourcer)
+}
+
+func (s *DefaultSourcer) APIKey() (string, error) {
+	return "", noDefault("API key")
+}
+
+func (s *DefaultSourcer) APISecret() (string, error) {
+	return "", noDefault("API secret")
+}
+
+func (s *DefaultSourcer) APICallTimeout() (time.Duration, error) {
+	return DefaultAPICallTimeout, nil
+}
+
+func (s *DefaultSourcer) CollectedGroupNames() ([]string, error) {
+	return nil, noDefault("collected group names")
+}
+
+func (s *DefaultSourcer) MetricsPort() (uint16, error) {
+	return DefaultMetricsPort, nil
+}
+
+func (s *DefaultSourcer) ComplianceRulesFile() (string, error) {
+	return "", noDefault("compliance rules file")
+}
+
+func (s *DefaultSourcer) UsageRefreshInterval() (time.Duration, error) {
+	return 0, noDefault("usage refresh interval")
+}
+
+func (s *DefaultSourcer) WebhookSecret() (string, error) {
+	return "", noDefault("webhook secret")
+}
+
+func (s *DefaultSourcer) WebhookReceiverURL() (string, error) {
+	return "", noDefault("webhook receiver URL")
+}
+
+func (s *DefaultSourcer) DestinationDiscovery() (bool, error) {
+	return false, noDefault("destination discovery")
+}
+
+func (s *DefaultSourcer) GroupIncludeRegex() (string, error) {
+	return "", noDefault("group include regex")
+}
+
+func (s *DefaultSourcer) GroupExcludeRegex() (string, error) {
+	return "", noDefault("group exclude regex")
+}
+
+func (s *DefaultSourcer) CollectAccount() (bool, error) {
+	return false, noDefault("collect account")
+}
+
+func (s *DefaultSourcer) CollectUsers() (bool, error) {
+	return false, noDefault("collect users")
+}
+
+func (s *DefaultSourcer) CollectTransformations() (bool, error) {
+	return false, noDefault("collect transformations")
+}
+
+func (s *DefaultSourcer) CollectAgents() (bool, error) {
+	return false, noDefault("collect agents")
+}
+
+func (s *DefaultSourcer) CollectInventory() (bool, error) {
+	return false, noDefault("collect inventory")
+}
+
+func (s *DefaultSourcer) ReloadToken() (string, error) {
+	return "", noDefault("reload token")
+}
+
+func (s *DefaultSourcer) Accounts() ([]*Account, error) {
+	return nil, noDefault("accounts")
+}
+
+// There is no default listen address, as it defaults to the metrics port
+func (s *DefaultSourcer) ListenAddresses() ([]string, error) {
+	return nil, noDefault("listen addresses")
+}
+
+func (s *DefaultSourcer) TelemetryPath() (string, error) {
+	return DefaultTelemetryPath, nil
+}
+
+func (s *DefaultSourcer) TLSCertFile() (string, error) {
+	return "", noDefault("TLS certificate file")
+}
+
+func (s *DefaultSourcer) TLSKeyFile() (string, error) {
+	return "", noDefault("TLS key file")
+}
+
+func (s *DefaultSourcer) WebConfigFile() (string, error) {
+	return "", noDefault("web config file")
+}
+
+func (s *DefaultSourcer) ReadinessWindow() (time.Duration, error) {
+	return DefaultReadinessWindow, nil
+}
+
+func (s *DefaultSourcer) ShutdownGracePeriod() (time.Duration, error) {
+	return DefaultShutdownGracePeriod, nil
+}
+
+func noDefault(setting string) error {
+	return fmt.Errorf("default %s: %w", setting, ErrNotSet)
+}
@@ -0,0 +1,406 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+configFile is the schema of the config file. The file may be YAML or JSON (JSON being
+a subset of YAML), and unknown fields are rejected. All fields are optional as far as
+the file is concerned; settings which are not present are reported as not set.
+
+	api:
+	  key: <string>
+	  secret: <string>
+	  call_timeout: <duration, e.g. 10s>
+	collected_groups:
+	  - <group name>
+	metrics_port: <port>
+	compliance_rules_file: <filename>
+	usage_refresh_interval: <positive duration, e.g. 6h>
+	webhook:
+	  secret: <string>
+	  receiver_url: <absolute HTTP(S) URL>
+	destination_discovery: <bool>
+	group_filter:
+	  include_regex: <regexp>
+	  exclude_regex: <regexp>
+	collect:
+	  account: <bool>
+	  users: <bool>
+	  transformations: <bool>
+	  agents: <bool>
+	  inventory: <bool>
+	reload:
+	  token: <string>
+	web:
+	  listen_addresses:
+	    - <host:port, or unix:<socket path>>
+	  telemetry_path: <path>
+	  tls:
+	    cert_file: <filename>
+	    key_file: <filename>
+	  config_file: <filename>
+	readiness:
+	  window: <positive duration, e.g. 5m>
+	shutdown:
+	  grace_period: <positive duration, e.g. 25s>
+	accounts:
+	  - name: <string>
+	    api:
+	      key: <string>
+	      secret: <string>
+	    collected_groups:
+	      - <group name>
+*/
+type configFile struct {
+	API struct {
+		Key         *string `yaml:"key"`
+		Secret      *string `yaml:"secret"`
+		CallTimeout *string `yaml:"call_timeout"`
+	} `yaml:"api"`
+	CollectedGroups      []string `yaml:"collected_groups"`
+	MetricsPort          *uint16  `yaml:"metrics_port"`
+	ComplianceRulesFile  *string  `yaml:"compliance_rules_file"`
+	UsageRefreshInterval *string  `yaml:"usage_refresh_interval"`
+	Webhook              struct {
+		Secret      *string `yaml:"secret"`
+		ReceiverURL *string `yaml:"receiver_url"`
+	} `yaml:"webhook"`
+	DestinationDiscovery *bool `yaml:"destination_discovery"`
+	GroupFilter          struct {
+		IncludeRegex *string `yaml:"include_regex"`
+		ExcludeRegex *string `yaml:"exclude_regex"`
+	} `yaml:"group_filter"`
+	Collect struct {
+		Account         *bool `yaml:"account"`
+		Users           *bool `yaml:"users"`
+		Transformations *bool `yaml:"transformations"`
+		Agents          *bool `yaml:"agents"`
+		Inventory       *bool `yaml:"inventory"`
+	} `yaml:"collect"`
+	Reload struct {
+		Token *string `yaml:"token"`
+	} `yaml:"reload"`
+	Web struct {
+		ListenAddresses []string `yaml:"listen_addresses"`
+		TelemetryPath   *string  `yaml:"telemetry_path"`
+		TLS             struct {
+			CertFile *string `yaml:"cert_file"`
+			KeyFile  *string `yaml:"key_file"`
+		} `yaml:"tls"`
+		ConfigFile *string `yaml:"config_file"`
+	} `yaml:"web"`
+	Readiness struct {
+		Window *string `yaml:"window"`
+	} `yaml:"readiness"`
+	Shutdown struct {
+		GracePeriod *string `yaml:"grace_period"`
+	} `yaml:"shutdown"`
+	Accounts []*configFileAccount `yaml:"accounts"`
+}
+
+// configFileAccount is the schema of a named account in the config file. All fields
+// of an account are required.
+type configFileAccount struct {
+	Name string `yaml:"name"`
+	API  struct {
+		Key    string `yaml:"key"`
+		Secret string `yaml:"secret"`
+	} `yaml:"api"`
+	CollectedGroups []string `yaml:"collected_groups"`
+}
+
+// FileSourcer sources the config from a YAML or JSON file. The file is read and
+// validated once, on construction.
+type FileSourcer struct {
+	Filename string
+
+	file                 *configFile
+	apiCallTimeout       time.Duration
+	usageRefreshInterval time.Duration
+	readinessWindow      time.Duration
+	shutdownGracePeriod  time.Duration
+	logger               *zap.SugaredLogger
+}
+
+func NewFileSourcer(logger *zap.SugaredLogger, filename string) (*FileSourcer, error) {
+	logger = getComponentLogger(logger, "file-sourcer")
+
+	file, err := os.Open(filename)
+	if err != nil {
+		logger.Errorw("opening config file", "filename", filename, "error", err)
+		return nil, fmt.Errorf("opening config file %q: %w", filename, err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+
+	configFile := new(configFile)
+	if err := decoder.Decode(configFile); err != nil {
+		logger.Errorw("decoding config file", "filename", filename, "error", err)
+		return nil, fmt.Errorf("decoding config file %q: %w", filename, err)
+	}
+
+	sourcer := &FileSourcer{
+		Filename: filename,
+		file:     configFile,
+		logger:   logger,
+	}
+
+	if err := sourcer.validate(); err != nil {
+		logger.Errorw("validating config file", "filename", filename, "error", err)
+		return nil, fmt.Errorf("validating config file %q: %w", filename, err)
+	}
+
+	logger.Infow("read config file", "filename", filename)
+	return sourcer, nil
+}
+
+func (s *FileSourcer) APIKey() (string, error) {
+	return getFileSetting(s, "api.key", s.file.API.Key)
+}
+
+func (s *FileSourcer) APISecret() (string, error) {
+	return getFileSetting(s, "api.secret", s.file.API.Secret)
+}
+
+func (s *FileSourcer) APICallTimeout() (time.Duration, error) {
+	if _, err := getFileSetting(s, "api.call_timeout", s.file.API.CallTimeout); err != nil {
+		return 0, err
+	}
+
+	return s.apiCallTimeout, nil
+}
+
+func (s *FileSourcer) CollectedGroupNames() ([]string, error) {
+	if len(s.file.CollectedGroups) == 0 {
+		return nil, s.notSet("collected_groups")
+	}
+
+	return s.file.CollectedGroups, nil
+}
+
+func (s *FileSourcer) MetricsPort() (uint16, error) {
+	return getFileSetting(s, "metrics_port", s.file.MetricsPort)
+}
+
+func (s *FileSourcer) ComplianceRulesFile() (string, error) {
+	return getFileSetting(s, "compliance_rules_file", s.file.ComplianceRulesFile)
+}
+
+func (s *FileSourcer) UsageRefreshInterval() (time.Duration, error) {
+	if _, err := getFileSetting(s, "usage_refresh_interval", s.file.UsageRefreshInterval); err != nil {
+		return 0, err
+	}
+
+	return s.usageRefreshInterval, nil
+}
+
+func (s *FileSourcer) WebhookSecret() (string, error) {
+	return getFileSetting(s, "webhook.secret", s.file.Webhook.Secret)
+}
+
+func (s *FileSourcer) WebhookReceiverURL() (string, error) {
+	return getFileSetting(s, "webhook.receiver_url", s.file.Webhook.ReceiverURL)
+}
+
+func (s *FileSourcer) DestinationDiscovery() (bool, error) {
+	return getFileSetting(s, "destination_discovery", s.file.DestinationDiscovery)
+}
+
+func (s *FileSourcer) GroupIncludeRegex() (string, error) {
+	return getFileSetting(s, "group_filter.include_regex", s.file.GroupFilter.IncludeRegex)
+}
+
+func (s *FileSourcer) GroupExcludeRegex() (string, error) {
+	return getFileSetting(s, "group_filter.exclude_regex", s.file.GroupFilter.ExcludeRegex)
+}
+
+func (s *FileSourcer) CollectAccount() (bool, error) {
+	return getFileSetting(s, "collect.account", s.file.Collect.Account)
+}
+
+func (s *FileSourcer) CollectUsers() (bool, error) {
+	return getFileSetting(s, "collect.users", s.file.Collect.Users)
+}
+
+func (s *FileSourcer) CollectTransformations() (bool, error) {
+	return getFileSetting(s, "collect.transformations", s.file.Collect.Transformations)
+}
+
+func (s *FileSourcer) CollectAgents() (bool, error) {
+	return getFileSetting(s, "collect.agents", s.file.Collect.Agents)
+}
+
+func (s *FileSourcer) CollectInventory() (bool, error) {
+	return getFileSetting(s, "collect.inventory", s.file.Collect.Inventory)
+}
+
+func (s *FileSourcer) ReloadToken() (string, error) {
+	return getFileSetting(s, "reload.token", s.file.Reload.Token)
+}
+
+func (s *FileSourcer) Accounts() ([]*Account, error) {
+	if len(s.file.Accounts) == 0 {
+		return nil, s.notSet("accounts")
+	}
+
+	accounts := make([]*Account, 0, len(s.file.Accounts))
+	for _, account := range s.file.Accounts {
+		accounts = append(accounts, &Account{
+			Name:                account.Name,
+			APIKey:              account.API.Key,
+			APISecret:           account.API.Secret,
+			CollectedGroupNames: account.CollectedGroups,
+		})
+	}
+
+	return accounts, nil
+}
+
+func (s *FileSourcer) ListenAddresses() ([]string, error) {
+	if len(s.file.Web.ListenAddresses) == 0 {
+		return nil, s.notSet("web.listen_addresses")
+	}
+
+	return s.file.Web.ListenAddresses, nil
+}
+
+func (s *FileSourcer) TelemetryPath() (string, error) {
+	return getFileSetting(s, "web.telemetry_path", s.file.Web.TelemetryPath)
+}
+
+func (s *FileSourcer) TLSCertFile() (string, error) {
+	return getFileSetting(s, "web.tls.cert_file", s.file.Web.TLS.CertFile)
+}
+
+func (s *FileSourcer) TLSKeyFile() (string, error) {
+	return getFileSetting(s, "web.tls.key_file", s.file.Web.TLS.KeyFile)
+}
+
+func (s *FileSourcer) WebConfigFile() (string, error) {
+	return getFileSetting(s, "web.config_file", s.file.Web.ConfigFile)
+}
+
+func (s *FileSourcer) ReadinessWindow() (time.Duration, error) {
+	if _, err := getFileSetting(s, "readiness.window", s.file.Readiness.Window); err != nil {
+		return 0, err
+	}
+
+	return s.readinessWindow, nil
+}
+
+func (s *FileSourcer) ShutdownGracePeriod() (time.Duration, error) {
+	if _, err := getFileSetting(s, "shutdown.grace_period", s.file.Shutdown.GracePeriod); err != nil {
+		return 0, err
+	}
+
+	return s.shutdownGracePeriod, nil
+}
+
+// validate checks the settings which are present, and parses those which are not
+// stored in the file in their final form
+func (s *FileSourcer) validate() error {
+	for _, name := range s.file.CollectedGroups {
+		if strings.Trim(name, " ") == "" {
+			return fmt.Errorf("invalid group name %q in collected_groups", name)
+		}
+	}
+
+	for i, account := range s.file.Accounts {
+		if account.Name == "" {
+			return fmt.Errorf("accounts[%d] missing name", i)
+		}
+
+		if account.API.Key == "" || account.API.Secret == "" {
+			return fmt.Errorf("account %q missing api.key or api.secret", account.Name)
+		}
+
+		if len(account.CollectedGroups) == 0 {
+			return fmt.Errorf("account %q missing collected_groups", account.Name)
+		}
+
+		for _, name := range account.CollectedGroups {
+			if strings.Trim(name, " ") == "" {
+				return fmt.Errorf("invalid group name %q in collected_groups of account %q", name, account.Name)
+			}
+		}
+	}
+
+	if s.file.API.CallTimeout != nil {
+		timeout, err := time.ParseDuration(*s.file.API.CallTimeout)
+		if err != nil {
+			return fmt.Errorf("parsing api.call_timeout %q: %w", *s.file.API.CallTimeout, err)
+		}
+		s.apiCallTimeout = timeout
+	}
+
+	if s.file.UsageRefreshInterval != nil {
+		interval, err := time.ParseDuration(*s.file.UsageRefreshInterval)
+		if err != nil {
+			return fmt.Errorf("parsing usage_refresh_interval %q: %w", *s.file.UsageRefreshInterval, err)
+		}
+
+		if interval <= 0 {
+			return fmt.Errorf("usage_refresh_interval %q not positive", *s.file.UsageRefreshInterval)
+		}
+		s.usageRefreshInterval = interval
+	}
+
+	if s.file.Readiness.Window != nil {
+		window, err := time.ParseDuration(*s.file.Readiness.Window)
+		if err != nil {
+			return fmt.Errorf("parsing readiness.window %q: %w", *s.file.Readiness.Window, err)
+		}
+
+		if window <= 0 {
+			return fmt.Errorf("readiness.window %q not positive", *s.file.Readiness.Window)
+		}
+		s.readinessWindow = window
+	}
+
+	if s.file.Shutdown.GracePeriod != nil {
+		period, err := time.ParseDuration(*s.file.Shutdown.GracePeriod)
+		if err != nil {
+			return fmt.Errorf("parsing shutdown.grace_period %q: %w", *s.file.Shutdown.GracePeriod, err)
+		}
+
+		if period <= 0 {
+			return fmt.Errorf("shutdown.grace_period %q not positive", *s.file.Shutdown.GracePeriod)
+		}
+		s.shutdownGracePeriod = period
+	}
+
+	if s.file.Webhook.ReceiverURL != nil {
+		if err := validateAbsoluteURL(*s.file.Webhook.ReceiverURL); err != nil {
+			return fmt.Errorf("parsing webhook.receiver_url %q: %w", *s.file.Webhook.ReceiverURL, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *FileSourcer) notSet(name string) error {
+	s.logger.Infow("config file setting not set", "filename", s.Filename, "name", name)
+	return fmt.Errorf("config file setting %q: %w", name, ErrNotSet)
+}
+
+// getFileSetting returns the value of an optional setting, or an ErrNotSet error if
+// the setting is not present in the file
+func getFileSetting[T any](s *FileSourcer, name string, value *T) (T, error) {
+	if value == nil {
+		var zero T
+		return zero, s.notSet(name)
+	}
+
+	s.logger.Infow("read config file setting", "filename", s.Filename, "name", name)
+	return *value, nil
+}
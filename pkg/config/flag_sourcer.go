@@ -0,0 +1,296 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	apiKeyFlag                 = "api.key"
+	apiCallTimeoutFlag         = "api.call-timeout"
+	collectedGroupsFlag        = "collected-groups"
+	metricsPortFlag            = "metrics.port"
+	complianceRulesFileFlag    = "compliance.rules-file"
+	usageRefreshIntervalFlag   = "usage.refresh-interval"
+	webhookReceiverURLFlag     = "webhook.receiver-url"
+	destinationDiscoveryFlag   = "destination.discovery"
+	groupIncludeRegexFlag      = "group-filter.include-regex"
+	groupExcludeRegexFlag      = "group-filter.exclude-regex"
+	collectAccountFlag         = "collect.account"
+	collectUsersFlag           = "collect.users"
+	collectTransformationsFlag = "collect.transformations"
+	collectAgentsFlag          = "collect.agents"
+	collectInventoryFlag       = "collect.inventory"
+	listenAddressesFlag        = "web.listen-addresses"
+	telemetryPathFlag          = "web.telemetry-path"
+	tlsCertFileFlag            = "web.tls-cert-file"
+	tlsKeyFileFlag             = "web.tls-key-file"
+	webConfigFileFlag          = "web.config.file"
+	readinessWindowFlag        = "readiness.window"
+	shutdownGracePeriodFlag    = "shutdown.grace-period"
+)
+
+// FlagSourcer sources the config from command-line flags. The flags are registered
+// on construction, and so the flag set must be parsed after construction. Flags which
+// are not given on the command line are reported as not set, even if they have
+// a zero value which would otherwise be valid.
+// Secrets are deliberately not accepted as flags, as they would be visible in the
+// process list.
+type FlagSourcer struct {
+	flagSet *flag.FlagSet
+
+	apiKey                 *string
+	apiCallTimeout         *time.Duration
+	collectedGroups        *string
+	metricsPort            *uint
+	complianceRulesFile    *string
+	usageRefreshInterval   *time.Duration
+	webhookReceiverURL     *string
+	destinationDiscovery   *bool
+	groupIncludeRegex      *string
+	groupExcludeRegex      *string
+	collectAccount         *bool
+	collectUsers           *bool
+	collectTransformations *bool
+	collectAgents          *bool
+	collectInventory       *bool
+	listenAddresses        *string
+	telemetryPath          *string
+	tlsCertFile            *string
+	tlsKeyFile             *string
+	webConfigFile          *string
+	readinessWindow        *time.Duration
+	shutdownGracePeriod    *time.Duration
+	logger                 *zap.SugaredLogger
+}
+
+func NewFlagSourcer(logger *zap.SugaredLogger, flagSet *flag.FlagSet) *FlagSourcer {
+	logger = getComponentLogger(logger, "flag-sourcer")
+
+	return &FlagSourcer{
+		flagSet:                flagSet,
+		apiKey:                 flagSet.String(apiKeyFlag, "", "Fivetran API key"),
+		apiCallTimeout:         flagSet.Duration(apiCallTimeoutFlag, 0, "Timeout of calls to the Fivetran API"),
+		collectedGroups:        flagSet.String(collectedGroupsFlag, "", "Comma-separated names of the groups to collect"),
+		metricsPort:            flagSet.Uint(metricsPortFlag, 0, "Port to serve metrics on"),
+		complianceRulesFile:    flagSet.String(complianceRulesFileFlag, "", "Path to the compliance rules file"),
+		usageRefreshInterval:   flagSet.Duration(usageRefreshIntervalFlag, 0, "Interval between refreshes of connector usage"),
+		webhookReceiverURL:     flagSet.String(webhookReceiverURLFlag, "", "URL of the webhook receiver, as reachable by Fivetran"),
+		destinationDiscovery:   flagSet.Bool(destinationDiscoveryFlag, false, "Discover the destinations of all groups"),
+		groupIncludeRegex:      flagSet.String(groupIncludeRegexFlag, "", "Regexp of collected and discovered group names to include"),
+		groupExcludeRegex:      flagSet.String(groupExcludeRegexFlag, "", "Regexp of collected and discovered group names to exclude"),
+		collectAccount:         flagSet.Bool(collectAccountFlag, false, "Collect account info"),
+		collectUsers:           flagSet.Bool(collectUsersFlag, false, "Collect users and teams"),
+		collectTransformations: flagSet.Bool(collectTransformationsFlag, false, "Collect dbt transformations"),
+		collectAgents:          flagSet.Bool(collectAgentsFlag, false, "Collect hybrid deployment agents"),
+		collectInventory:       flagSet.Bool(collectInventoryFlag, false, "Collect webhooks, private links and external logging"),
+		listenAddresses:        flagSet.String(listenAddressesFlag, "", "Comma-separated addresses to listen on, with unix: prefixing socket paths"),
+		telemetryPath:          flagSet.String(telemetryPathFlag, "", "Path to serve metrics under"),
+		tlsCertFile:            flagSet.String(tlsCertFileFlag, "", "Path to the TLS certificate file"),
+		tlsKeyFile:             flagSet.String(tlsKeyFileFlag, "", "Path to the TLS key file"),
+		webConfigFile:          flagSet.String(webConfigFileFlag, "", "Path to the web config file of basic auth users and bearer tokens"),
+		readinessWindow:        flagSet.Duration(readinessWindowFlag, 0, "Window within which every collected group must have been refreshed to be ready"),
+		shutdownGracePeriod:    flagSet.Duration(shutdownGracePeriodFlag, 0, "Period to wait for in-flight requests to complete on shutdown"),
+		logger:                 logger,
+	}
+}
+
+func (s *FlagSourcer) APIKey() (string, error) {
+	return getFlag(s, apiKeyFlag, s.apiKey)
+}
+
+func (s *FlagSourcer) APISecret() (string, error) {
+	return "", fmt.Errorf("API secret flag: %w", ErrNotSet)
+}
+
+func (s *FlagSourcer) APICallTimeout() (time.Duration, error) {
+	return getFlag(s, apiCallTimeoutFlag, s.apiCallTimeout)
+}
+
+func (s *FlagSourcer) CollectedGroupNames() ([]string, error) {
+	return getCSVFlag(s, collectedGroupsFlag, s.collectedGroups)
+}
+
+func (s *FlagSourcer) MetricsPort() (uint16, error) {
+	port, err := getFlag(s, metricsPortFlag, s.metricsPort)
+	if err != nil {
+		return 0, err
+	}
+
+	if port > 65535 {
+		s.logger.Errorw("metrics port out of range", "port", port)
+		return 0, fmt.Errorf("metrics port %d out of range", port)
+	}
+
+	return uint16(port), nil
+}
+
+func (s *FlagSourcer) ComplianceRulesFile() (string, error) {
+	return getFlag(s, complianceRulesFileFlag, s.complianceRulesFile)
+}
+
+func (s *FlagSourcer) UsageRefreshInterval() (time.Duration, error) {
+	interval, err := getFlag(s, usageRefreshIntervalFlag, s.usageRefreshInterval)
+	if err != nil {
+		return 0, err
+	}
+
+	if interval <= 0 {
+		s.logger.Errorw("usage refresh interval not positive", "interval", interval)
+		return 0, fmt.Errorf("usage refresh interval %s not positive", interval)
+	}
+
+	return interval, nil
+}
+
+func (s *FlagSourcer) WebhookSecret() (string, error) {
+	return "", fmt.Errorf("webhook secret flag: %w", ErrNotSet)
+}
+
+func (s *FlagSourcer) WebhookReceiverURL() (string, error) {
+	urlStr, err := getFlag(s, webhookReceiverURLFlag, s.webhookReceiverURL)
+	if err != nil {
+		return "", err
+	}
+
+	if err := validateAbsoluteURL(urlStr); err != nil {
+		s.logger.Errorw("parsing webhook receiver URL", "url", urlStr, "error", err)
+		return "", fmt.Errorf("parsing webhook receiver URL %q: %w", urlStr, err)
+	}
+
+	return urlStr, nil
+}
+
+func (s *FlagSourcer) DestinationDiscovery() (bool, error) {
+	return getFlag(s, destinationDiscoveryFlag, s.destinationDiscovery)
+}
+
+func (s *FlagSourcer) GroupIncludeRegex() (string, error) {
+	return getFlag(s, groupIncludeRegexFlag, s.groupIncludeRegex)
+}
+
+func (s *FlagSourcer) GroupExcludeRegex() (string, error) {
+	return getFlag(s, groupExcludeRegexFlag, s.groupExcludeRegex)
+}
+
+func (s *FlagSourcer) CollectAccount() (bool, error) {
+	return getFlag(s, collectAccountFlag, s.collectAccount)
+}
+
+func (s *FlagSourcer) CollectUsers() (bool, error) {
+	return getFlag(s, collectUsersFlag, s.collectUsers)
+}
+
+func (s *FlagSourcer) CollectTransformations() (bool, error) {
+	return getFlag(s, collectTransformationsFlag, s.collectTransformations)
+}
+
+func (s *FlagSourcer) CollectAgents() (bool, error) {
+	return getFlag(s, collectAgentsFlag, s.collectAgents)
+}
+
+func (s *FlagSourcer) CollectInventory() (bool, error) {
+	return getFlag(s, collectInventoryFlag, s.collectInventory)
+}
+
+func (s *FlagSourcer) ReloadToken() (string, error) {
+	return "", fmt.Errorf("reload token flag: %w", ErrNotSet)
+}
+
+// Accounts are not accepted as flags, as they contain secrets
+func (s *FlagSourcer) Accounts() ([]*Account, error) {
+	return nil, fmt.Errorf("accounts flag: %w", ErrNotSet)
+}
+
+func (s *FlagSourcer) ListenAddresses() ([]string, error) {
+	return getCSVFlag(s, listenAddressesFlag, s.listenAddresses)
+}
+
+func (s *FlagSourcer) TelemetryPath() (string, error) {
+	return getFlag(s, telemetryPathFlag, s.telemetryPath)
+}
+
+func (s *FlagSourcer) TLSCertFile() (string, error) {
+	return getFlag(s, tlsCertFileFlag, s.tlsCertFile)
+}
+
+func (s *FlagSourcer) TLSKeyFile() (string, error) {
+	return getFlag(s, tlsKeyFileFlag, s.tlsKeyFile)
+}
+
+func (s *FlagSourcer) WebConfigFile() (string, error) {
+	return getFlag(s, webConfigFileFlag, s.webConfigFile)
+}
+
+func (s *FlagSourcer) ReadinessWindow() (time.Duration, error) {
+	window, err := getFlag(s, readinessWindowFlag, s.readinessWindow)
+	if err != nil {
+		return 0, err
+	}
+
+	if window <= 0 {
+		s.logger.Errorw("readiness window not positive", "window", window)
+		return 0, fmt.Errorf("readiness window %s not positive", window)
+	}
+
+	return window, nil
+}
+
+func (s *FlagSourcer) ShutdownGracePeriod() (time.Duration, error) {
+	period, err := getFlag(s, shutdownGracePeriodFlag, s.shutdownGracePeriod)
+	if err != nil {
+		return 0, err
+	}
+
+	if period <= 0 {
+		s.logger.Errorw("shutdown grace period not positive", "period", period)
+		return 0, fmt.Errorf("shutdown grace period %s not positive", period)
+	}
+
+	return period, nil
+}
+
+func getCSVFlag(s *FlagSourcer, name string, value *string) ([]string, error) {
+	csv, err := getFlag(s, name, value)
+	if err != nil {
+		return nil, err
+	}
+
+	split := strings.Split(csv, ",")
+	trimmedValues := make([]string, 0, len(split))
+	for _, value := range split {
+		trimmed := strings.Trim(value, " ")
+		if trimmed == "" {
+			s.logger.Errorw("invalid value in flag", "name", name)
+			return nil, fmt.Errorf("invalid value in flag %q", name)
+		}
+
+		trimmedValues = append(trimmedValues, trimmed)
+	}
+
+	return trimmedValues, nil
+}
+
+// getFlag returns the value of a flag, or an ErrNotSet error if the flag was not
+// given on the command line
+func getFlag[T any](s *FlagSourcer, name string, value *T) (T, error) {
+	given := false
+	s.flagSet.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			given = true
+		}
+	})
+
+	if !given {
+		var zero T
+		s.logger.Infow("flag not given", "name", name)
+		return zero, fmt.Errorf("flag %q: %w", name, ErrNotSet)
+	}
+
+	s.logger.Infow("read flag", "name", name)
+	return *value, nil
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Layer is a named Sourcer in a LayeredSourcer. The name is logged as the source of
+// the settings the layer provides.
+type Layer struct {
+	Name    string
+	Sourcer Sourcer
+}
+
+// LayeredSourcer sources each setting from the first of its layers in which the
+// setting is set, so earlier layers take precedence over later ones. An error other
+// than ErrNotSet from any layer is returned rather than falling through, so that an
+// invalid setting is never silently overridden by a lower-precedence one.
+type LayeredSourcer struct {
+	Layers []*Layer
+
+	logger *zap.SugaredLogger
+}
+
+func NewLayeredSourcer(logger *zap.SugaredLogger, layers ...*Layer) *LayeredSourcer {
+	logger = getComponentLogger(logger, "layered-sourcer")
+
+	return &LayeredSourcer{
+		Layers: layers,
+		logger: logger,
+	}
+}
+
+func (s *LayeredSourcer) APIKey() (string, error) {
+	return getLayered(s, "API key", Sourcer.APIKey)
+}
+
+func (s *LayeredSourcer) APISecret() (string, error) {
+	return getLayered(s, "API secret", Sourcer.APISecret)
+}
+
+func (s *LayeredSourcer) APICallTimeout() (time.Duration, error) {
+	return getLayered(s, "API call timeout", Sourcer.APICallTimeout)
+}
+
+func (s *LayeredSourcer) CollectedGroupNames() ([]string, error) {
+	return getLayered(s, "collected group names", Sourcer.CollectedGroupNames)
+}
+
+func (s *LayeredSourcer) MetricsPort() (uint16, error) {
+	return getLayered(s, "metrics port", Sourcer.MetricsPort)
+}
+
+func (s *LayeredSourcer) ComplianceRulesFile() (string, error) {
+	return getLayered(s, "compliance rules file", Sourcer.ComplianceRulesFile)
+}
+
+func (s *LayeredSourcer) UsageRefreshInterval() (time.Duration, error) {
+	return getLayered(s, "usage refresh interval", Sourcer.UsageRefreshInterval)
+}
+
+func (s *LayeredSourcer) WebhookSecret() (string, error) {
+	return getLayered(s, "webhook secret", Sourcer.WebhookSecret)
+}
+
+func (s *LayeredSourcer) WebhookReceiverURL() (string, error) {
+	return getLayered(s, "webhook receiver URL", Sourcer.WebhookReceiverURL)
+}
+
+func (s *LayeredSourcer) DestinationDiscovery() (bool, error) {
+	return getLayered(s, "destination discovery", Sourcer.DestinationDiscovery)
+}
+
+func (s *LayeredSourcer) GroupIncludeRegex() (string, error) {
+	return getLayered(s, "group include regex", Sourcer.GroupIncludeRegex)
+}
+
+func (s *LayeredSourcer) GroupExcludeRegex() (string, error) {
+	return getLayered(s, "group exclude regex", Sourcer.GroupExcludeRegex)
+}
+
+func (s *LayeredSourcer) CollectAccount() (bool, error) {
+	return getLayered(s, "collect account", Sourcer.CollectAccount)
+}
+
+func (s *LayeredSourcer) CollectUsers() (bool, error) {
+	return getLayered(s, "collect users", Sourcer.CollectUsers)
+}
+
+func (s *LayeredSourcer) CollectTransformations() (bool, error) {
+	return getLayered(s, "collect transformations", Sourcer.CollectTransformations)
+}
+
+func (s *LayeredSourcer) CollectAgents() (bool, error) {
+	return getLayered(s, "collect agents", Sourcer.CollectAgents)
+}
+
+func (s *LayeredSourcer) CollectInventory() (bool, error) {
+	return getLayered(s, "collect inventory", Sourcer.CollectInventory)
+}
+
+func (s *LayeredSourcer) ReloadToken() (string, error) {
+	return getLayered(s, "reload token", Sourcer.ReloadToken)
+}
+
+func (s *LayeredSourcer) Accounts() ([]*Account, error) {
+	return getLayered(s, "accounts", Sourcer.Accounts)
+}
+
+func (s *LayeredSourcer) ListenAddresses() ([]string, error) {
+	return getLayered(s, "listen addresses", Sourcer.ListenAddresses)
+}
+
+func (s *LayeredSourcer) TelemetryPath() (string, error) {
+	return getLayered(s, "telemetry path", Sourcer.TelemetryPath)
+}
+
+func (s *LayeredSourcer) TLSCertFile() (string, error) {
+	return getLayered(s, "TLS certificate file", Sourcer.TLSCertFile)
+}
+
+func (s *LayeredSourcer) TLSKeyFile() (string, error) {
+	return getLayered(s, "TLS key file", Sourcer.TLSKeyFile)
+}
+
+func (s *LayeredSourcer) WebConfigFile() (string, error) {
+	return getLayered(s, "web config file", Sourcer.WebConfigFile)
+}
+
+func (s *LayeredSourcer) ReadinessWindow() (time.Duration, error) {
+	return getLayered(s, "readiness window", Sourcer.ReadinessWindow)
+}
+
+func (s *LayeredSourcer) ShutdownGracePeriod() (time.Duration, error) {
+	return getLayered(s, "shutdown grace period", Sourcer.ShutdownGracePeriod)
+}
+
+// getLayered gets a setting from the first layer in which it is set
+func getLayered[T any](s *LayeredSourcer, setting string, get func(Sourcer) (T, error)) (T, error) {
+	var zero T
+
+	for _, layer := range s.Layers {
+		value, err := get(layer.Sourcer)
+		if errors.Is(err, ErrNotSet) {
+			continue
+		}
+
+		if err != nil {
+			s.logger.Errorw("getting config setting", "setting", setting, "source", layer.Name, "error", err)
+			return zero, fmt.Errorf("getting %s from %s: %w", setting, layer.Name, err)
+		}
+
+		s.logger.Infow("sourced config setting", "setting", setting, "source", layer.Name)
+		return value, nil
+	}
+
+	s.logger.Infow("config setting not set in any source", "setting", setting)
+	return zero, fmt.Errorf("%s: %w", setting, ErrNotSet)
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/secret"
+)
+
+const (
+	apiKeySecretKey        = "api_key"
+	apiSecretSecretKey     = "api_secret"
+	webhookSecretSecretKey = "webhook_secret"
+	reloadTokenSecretKey   = "reload_token"
+)
+
+// SecretSourcer sources the secret settings from a secret provider, under the keys
+// api_key, api_secret, webhook_secret and reload_token. All other settings are reported as not set.
+type SecretSourcer struct {
+	Provider secret.Provider
+}
+
+func NewSecretSourcer(provider secret.Provider) *SecretSourcer {
+	return &SecretSourcer{provider}
+}
+
+func (s *SecretSourcer) APIKey() (string, error) {
+	return s.getSecret(apiKeySecretKey)
+}
+
+func (s *SecretSourcer) APISecret() (string, error) {
+	return s.getSecret(apiSecretSecretKey)
+}
+
+func (s *SecretSourcer) APICallTimeout() (time.Duration, error) {
+	return 0, notSecret("API call timeout")
+}
+
+func (s *SecretSourcer) CollectedGroupNames() ([]string, error) {
+	return nil, notSecret("collected group names")
+}
+
+func (s *SecretSourcer) MetricsPort() (uint16, error) {
+	return 0, notSecret("metrics port")
+}
+
+func (s *SecretSourcer) ComplianceRulesFile() (string, error) {
+	return "", notSecret("compliance rules file")
+}
+
+func (s *SecretSourcer) UsageRefreshInterval() (time.Duration, error) {
+	return 0, notSecret("usage refresh interval")
+}
+
+func (s *SecretSourcer) WebhookSecret() (string, error) {
+	return s.getSecret(webhookSecretSecretKey)
+}
+
+func (s *SecretSourcer) WebhookReceiverURL() (string, error) {
+	return "", notSecret("webhook receiver URL")
+}
+
+func (s *SecretSourcer) DestinationDiscovery() (bool, error) {
+	return false, notSecret("destination discovery")
+}
+
+func (s *SecretSourcer) GroupIncludeRegex() (string, error) {
+	return "", notSecret("group include regex")
+}
+
+func (s *SecretSourcer) GroupExcludeRegex() (string, error) {
+	return "", notSecret("group exclude regex")
+}
+
+func (s *SecretSourcer) CollectAccount() (bool, error) {
+	return false, notSecret("collect account")
+}
+
+func (s *SecretSourcer) CollectUsers() (bool, error) {
+	return false, notSecret("collect users")
+}
+
+func (s *SecretSourcer) CollectTransformations() (bool, error) {
+	return false, notSecret("collect transformations")
+}
+
+func (s *SecretSourcer) CollectAgents() (bool, error) {
+	return false, notSecret("collect agents")
+}
+
+func (s *SecretSourcer) CollectInventory() (bool, error) {
+	return false, notSecret("collect inventory")
+}
+
+func (s *SecretSourcer) ReloadToken() (string, error) {
+	return s.getSecret(reloadTokenSecretKey)
+}
+
+func (s *SecretSourcer) Accounts() ([]*Account, error) {
+	return nil, notSecret("accounts")
+}
+
+func (s *SecretSourcer) ListenAddresses() ([]string, error) {
+	return nil, notSecret("listen addresses")
+}
+
+func (s *SecretSourcer) TelemetryPath() (string, error) {
+	return "", notSecret("telemetry path")
+}
+
+func (s *SecretSourcer) TLSCertFile() (string, error) {
+	return "", notSecret("TLS certificate file")
+}
+
+func (s *SecretSourcer) TLSKeyFile() (string, error) {
+	return "", notSecret("TLS key file")
+}
+
+func (s *SecretSourcer) WebConfigFile() (string, error) {
+	return "", notSecret("web config file")
+}
+
+func (s *SecretSourcer) ReadinessWindow() (time.Duration, error) {
+	return 0, notSecret("readiness window")
+}
+
+func (s *SecretSourcer) ShutdownGracePeriod() (time.Duration, error) {
+	return 0, notSecret("shutdown grace period")
+}
+
+func (s *SecretSourcer) getSecret(key string) (string, error) {
+	value, err := s.Provider.Get(key)
+	if errors.Is(err, secret.ErrNotFound) {
+		return "", fmt.Errorf("secret %q: %w", key, ErrNotSet)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("getting secret %q: %w", key, err)
+	}
+
+	return value, nil
+}
+
+func notSecret(setting string) error {
+	return fmt.Errorf("%s is not a secret: %w", setting, ErrNotSet)
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/secret"
+	"go.uber.org/zap"
+)
+
+const (
+	testVaultToken = "test-token"
+	testVaultMount = "kv"
+	testVaultPath  = "fivetran/exporter"
+)
+
+// newTestVaultServer stands in for a Vault server with a KV version 2 secrets engine,
+// holding a single secret with the API key and secret set, but not the webhook secret
+// or reload token
+func newTestVaultServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != testVaultToken {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/"+testVaultMount+"/data/"+testVaultPath {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"data": {
+				"data": {"` + apiKeySecretKey + `": "k3y", "` + apiSecretSecretKey + `": "s3cr3t"},
+				"metadata": {"version": 1}
+			}
+		}`))
+	}))
+}
+
+func newTestSecretSourcer(t *testing.T, address, token string) *SecretSourcer {
+	t.Helper()
+
+	provider, err := secret.NewVaultKVProvider(zap.NewNop().Sugar(), address, token, testVaultMount, testVaultPath, time.Second)
+	if err != nil {
+		t.Fatalf("constructing Vault KV provider: %v", err)
+	}
+
+	return NewSecretSourcer(provider)
+}
+
+func TestSecretSourcerAPICredentials(t *testing.T) {
+	server := newTestVaultServer(t)
+	defer server.Close()
+
+	sourcer := newTestSecretSourcer(t, server.URL, testVaultToken)
+
+	apiKey, err := sourcer.APIKey()
+	if err != nil {
+		t.Fatalf("getting API key: %v", err)
+	}
+
+	if apiKey != "k3y" {
+		t.Errorf("got API key %q, want %q", apiKey, "k3y")
+	}
+
+	apiSecret, err := sourcer.APISecret()
+	if err != nil {
+		t.Fatalf("getting API secret: %v", err)
+	}
+
+	if apiSecret != "s3cr3t" {
+		t.Errorf("got API secret %q, want %q", apiSecret, "s3cr3t")
+	}
+}
+
+func TestSecretSourcerMissingKey(t *testing.T) {
+	server := newTestVaultServer(t)
+	defer server.Close()
+
+	sourcer := newTestSecretSourcer(t, server.URL, testVaultToken)
+
+	// A key missing from the secret falls through to the lower-precedence config layers
+	if _, err := sourcer.WebhookSecret(); !errors.Is(err, ErrNotSet) {
+		t.Errorf("got error %v, want %v", err, ErrNotSet)
+	}
+
+	if _, err := sourcer.ReloadToken(); !errors.Is(err, ErrNotSet) {
+		t.Errorf("got error %v, want %v", err, ErrNotSet)
+	}
+}
+
+func TestSecretSourcerForbidden(t *testing.T) {
+	server := newTestVaultServer(t)
+	defer server.Close()
+
+	sourcer := newTestSecretSourcer(t, server.URL, "wrong-token")
+
+	_, err := sourcer.APIKey()
+	if err == nil {
+		t.Fatal("got no error, want error")
+	}
+
+	// A rejected token must not fall through to the lower-precedence config layers
+	if errors.Is(err, ErrNotSet) {
+		t.Errorf("got error %v, want error other than %v", err, ErrNotSet)
+	}
+}
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -16,14 +19,98 @@ const (
 	timeoutEnvVar     = "FIVETRAN_API_CALL_TIMEOUT"
 	groupsEnvVar      = "FIVETRAN_COLLECTED_GROUPIDS_CSV"
 	metricsPortEnvVar = "METRICS_PORT"
+
+	complianceRulesFileEnvVar    = "FIVETRAN_COMPLIANCE_RULES_FILE"
+	usageRefreshIntervalEnvVar   = "FIVETRAN_USAGE_REFRESH_INTERVAL"
+	webhookSecretEnvVar          = "FIVETRAN_WEBHOOK_SECRET"
+	webhookReceiverURLEnvVar     = "FIVETRAN_WEBHOOK_RECEIVER_URL"
+	destinationDiscoveryEnvVar   = "FIVETRAN_DESTINATION_DISCOVERY"
+	groupIncludeRegexEnvVar      = "FIVETRAN_GROUP_INCLUDE_REGEX"
+	groupExcludeRegexEnvVar      = "FIVETRAN_GROUP_EXCLUDE_REGEX"
+	collectAccountEnvVar         = "FIVETRAN_COLLECT_ACCOUNT"
+	collectUsersEnvVar           = "FIVETRAN_COLLECT_USERS"
+	collectTransformationsEnvVar = "FIVETRAN_COLLECT_TRANSFORMATIONS"
+	collectInventoryEnvVar       = "FIVETRAN_COLLECT_INVENTORY"
+	collectAgentsEnvVar          = "FIVETRAN_COLLECT_AGENTS"
+	reloadTokenEnvVar            = "FIVETRAN_RELOAD_TOKEN"
+	accountsEnvVar               = "FIVETRAN_ACCOUNTS_CSV"
+	listenAddressesEnvVar        = "WEB_LISTEN_ADDRESSES_CSV"
+	telemetryPathEnvVar          = "WEB_TELEMETRY_PATH"
+	tlsCertFileEnvVar            = "WEB_TLS_CERT_FILE"
+	tlsKeyFileEnvVar             = "WEB_TLS_KEY_FILE"
+	webConfigFileEnvVar          = "WEB_CONFIG_FILE"
+	readinessWindowEnvVar        = "FIVETRAN_READINESS_WINDOW"
+	shutdownGracePeriodEnvVar    = "FIVETRAN_SHUTDOWN_GRACE_PERIOD"
+
+	// The settings of each named account are read from the environment variables
+	// with this prefix, followed by the upper-cased account name and the setting,
+	// e.g. FIVETRAN_ACCOUNT_EU_API_KEY
+	accountEnvVarPrefix          = "FIVETRAN_ACCOUNT_"
+	accountAPIKeyEnvVarSuffix    = "_API_KEY"
+	accountAPISecretEnvVarSuffix = "_API_SECRET"
+	accountGroupsEnvVarSuffix    = "_COLLECTED_GROUPIDS_CSV"
+
+	vaultAddrEnvVar    = "VAULT_ADDR"
+	vaultTokenEnvVar   = "VAULT_TOKEN"
+	vaultKVMountEnvVar = "FIVETRAN_VAULT_KV_MOUNT"
+	vaultKVPathEnvVar  = "FIVETRAN_VAULT_KV_PATH"
+
+	defaultVaultKVMount = "secret"
+
+	// Secrets may instead be read from the file named by the environment variable
+	// with this suffix, e.g. FIVETRAN_API_SECRET_FILE
+	fileEnvVarSuffix = "_FILE"
 )
 
+// VaultKVConfig locates the secret in a Vault KV version 2 secrets engine from which
+// secrets are read
+type VaultKVConfig struct {
+	Address string
+	Token   string
+	Mount   string
+	Path    string
+}
+
+// Account is a named Fivetran account to collect from, with its own API credentials
+// and collected groups
+type Account struct {
+	Name                string
+	APIKey              string
+	APISecret           string
+	CollectedGroupNames []string
+}
+
+// ErrNotSet is returned (wrapped) by a Sourcer when a setting has not been provided.
+// Optional settings can be detected with errors.Is(err, ErrNotSet).
+var ErrNotSet = errors.New("not set")
+
 type Sourcer interface {
 	APIKey() (string, error)
 	APISecret() (string, error)
 	APICallTimeout() (time.Duration, error)
 	CollectedGroupNames() ([]string, error)
 	MetricsPort() (uint16, error)
+	ComplianceRulesFile() (string, error)
+	UsageRefreshInterval() (time.Duration, error)
+	WebhookSecret() (string, error)
+	WebhookReceiverURL() (string, error)
+	DestinationDiscovery() (bool, error)
+	GroupIncludeRegex() (string, error)
+	GroupExcludeRegex() (string, error)
+	CollectAccount() (bool, error)
+	CollectUsers() (bool, error)
+	CollectTransformations() (bool, error)
+	CollectAgents() (bool, error)
+	CollectInventory() (bool, error)
+	ReloadToken() (string, error)
+	Accounts() ([]*Account, error)
+	ListenAddresses() ([]string, error)
+	TelemetryPath() (string, error)
+	TLSCertFile() (string, error)
+	TLSKeyFile() (string, error)
+	WebConfigFile() (string, error)
+	ReadinessWindow() (time.Duration, error)
+	ShutdownGracePeriod() (time.Duration, error)
 }
 
 type EnvVarSourcer struct {
@@ -36,11 +123,11 @@ func NewEnvVarSourcer(logger *zap.SugaredLogger) *EnvVarSourcer {
 }
 
 func (s *EnvVarSourcer) APIKey() (string, error) {
-	return s.getEnvVar(apiKeyEnvVar)
+	return s.getSecretEnvVar(apiKeyEnvVar)
 }
 
 func (s *EnvVarSourcer) APISecret() (string, error) {
-	return s.getEnvVar(apiSecretEnvVar)
+	return s.getSecretEnvVar(apiSecretEnvVar)
 }
 
 func (s *EnvVarSourcer) APICallTimeout() (time.Duration, error) {
@@ -59,39 +146,337 @@ func (s *EnvVarSourcer) APICallTimeout() (time.Duration, error) {
 }
 
 func (s *EnvVarSourcer) CollectedGroupNames() ([]string, error) {
-	csv, err := s.getEnvVar(groupsEnvVar)
+	return s.getCSVEnvVar(groupsEnvVar)
+}
+
+func (s *EnvVarSourcer) MetricsPort() (uint16, error) {
+	portStr, err := s.getEnvVar(metricsPortEnvVar)
+	if err != nil {
+		return 0, err
+	}
+
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		s.logger.Errorw("parsing metrics port", "port", portStr, "error", err)
+		return 0, fmt.Errorf("parsing metrics port %q: %w", portStr, err)
+	}
+
+	return uint16(port), nil
+}
+
+func (s *EnvVarSourcer) ComplianceRulesFile() (string, error) {
+	return s.getEnvVar(complianceRulesFileEnvVar)
+}
+
+func (s *EnvVarSourcer) UsageRefreshInterval() (time.Duration, error) {
+	intervalStr, err := s.getEnvVar(usageRefreshIntervalEnvVar)
+	if err != nil {
+		return 0, err
+	}
+
+	interval, err := time.ParseDuration(intervalStr)
+	if err != nil {
+		s.logger.Errorw("parsing usage refresh interval", "interval", intervalStr, "error", err)
+		return 0, fmt.Errorf("parsing usage refresh interval %q: %w", intervalStr, err)
+	}
+
+	if interval <= 0 {
+		s.logger.Errorw("usage refresh interval not positive", "interval", intervalStr)
+		return 0, fmt.Errorf("usage refresh interval %q not positive", intervalStr)
+	}
+
+	return interval, nil
+}
+
+func (s *EnvVarSourcer) WebhookSecret() (string, error) {
+	return s.getSecretEnvVar(webhookSecretEnvVar)
+}
+
+func (s *EnvVarSourcer) WebhookReceiverURL() (string, error) {
+	urlStr, err := s.getEnvVar(webhookReceiverURLEnvVar)
+	if err != nil {
+		return "", err
+	}
+
+	if err := validateAbsoluteURL(urlStr); err != nil {
+		s.logger.Errorw("parsing webhook receiver URL", "url", urlStr, "error", err)
+		return "", fmt.Errorf("parsing webhook receiver URL %q: %w", urlStr, err)
+	}
+
+	return urlStr, nil
+}
+
+func (s *EnvVarSourcer) DestinationDiscovery() (bool, error) {
+	return s.getBoolEnvVar(destinationDiscoveryEnvVar)
+}
+
+func (s *EnvVarSourcer) GroupIncludeRegex() (string, error) {
+	return s.getEnvVar(groupIncludeRegexEnvVar)
+}
+
+func (s *EnvVarSourcer) GroupExcludeRegex() (string, error) {
+	return s.getEnvVar(groupExcludeRegexEnvVar)
+}
+
+func (s *EnvVarSourcer) CollectAccount() (bool, error) {
+	return s.getBoolEnvVar(collectAccountEnvVar)
+}
+
+func (s *EnvVarSourcer) CollectUsers() (bool, error) {
+	return s.getBoolEnvVar(collectUsersEnvVar)
+}
+
+func (s *EnvVarSourcer) CollectTransformations() (bool, error) {
+	return s.getBoolEnvVar(collectTransformationsEnvVar)
+}
+
+func (s *EnvVarSourcer) CollectAgents() (bool, error) {
+	return s.getBoolEnvVar(collectAgentsEnvVar)
+}
+
+func (s *EnvVarSourcer) CollectInventory() (bool, error) {
+	return s.getBoolEnvVar(collectInventoryEnvVar)
+}
+
+func (s *EnvVarSourcer) ReloadToken() (string, error) {
+	return s.getSecretEnvVar(reloadTokenEnvVar)
+}
+
+func (s *EnvVarSourcer) Accounts() ([]*Account, error) {
+	names, err := s.getCSVEnvVar(accountsEnvVar)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]*Account, 0, len(names))
+	for _, name := range names {
+		prefix := accountEnvVarPrefix + envVarAccountName(name)
+
+		// All settings of a named account are required
+		apiKey, err := s.getSecretEnvVar(prefix + accountAPIKeyEnvVarSuffix)
+		if err != nil {
+			s.logger.Errorw("getting account API key", "account", name, "error", err)
+			return nil, fmt.Errorf("getting API key of account %q: %w", name, err)
+		}
+
+		apiSecret, err := s.getSecretEnvVar(prefix + accountAPISecretEnvVarSuffix)
+		if err != nil {
+			s.logger.Errorw("getting account API secret", "account", name, "error", err)
+			return nil, fmt.Errorf("getting API secret of account %q: %w", name, err)
+		}
+
+		groupNames, err := s.getCSVEnvVar(prefix + accountGroupsEnvVarSuffix)
+		if err != nil {
+			s.logger.Errorw("getting account collected group names", "account", name, "error", err)
+			return nil, fmt.Errorf("getting collected group names of account %q: %w", name, err)
+		}
+
+		accounts = append(accounts, &Account{
+			Name:                name,
+			APIKey:              apiKey,
+			APISecret:           apiSecret,
+			CollectedGroupNames: groupNames,
+		})
+	}
+
+	return accounts, nil
+}
+
+func (s *EnvVarSourcer) ListenAddresses() ([]string, error) {
+	return s.getCSVEnvVar(listenAddressesEnvVar)
+}
+
+func (s *EnvVarSourcer) TelemetryPath() (string, error) {
+	return s.getEnvVar(telemetryPathEnvVar)
+}
+
+func (s *EnvVarSourcer) TLSCertFile() (string, error) {
+	return s.getEnvVar(tlsCertFileEnvVar)
+}
+
+func (s *EnvVarSourcer) TLSKeyFile() (string, error) {
+	return s.getEnvVar(tlsKeyFileEnvVar)
+}
+
+func (s *EnvVarSourcer) WebConfigFile() (string, error) {
+	return s.getEnvVar(webConfigFileEnvVar)
+}
+
+func (s *EnvVarSourcer) ReadinessWindow() (time.Duration, error) {
+	windowStr, err := s.getEnvVar(readinessWindowEnvVar)
+	if err != nil {
+		return 0, err
+	}
+
+	window, err := time.ParseDuration(windowStr)
+	if err != nil {
+		s.logger.Errorw("parsing readiness window", "window", windowStr, "error", err)
+		return 0, fmt.Errorf("parsing readiness window %q: %w", windowStr, err)
+	}
+
+	if window <= 0 {
+		s.logger.Errorw("readiness window not positive", "window", windowStr)
+		return 0, fmt.Errorf("readiness window %q not positive", windowStr)
+	}
+
+	return window, nil
+}
+
+func (s *EnvVarSourcer) ShutdownGracePeriod() (time.Duration, error) {
+	periodStr, err := s.getEnvVar(shutdownGracePeriodEnvVar)
+	if err != nil {
+		return 0, err
+	}
+
+	period, err := time.ParseDuration(periodStr)
+	if err != nil {
+		s.logger.Errorw("parsing shutdown grace period", "period", periodStr, "error", err)
+		return 0, fmt.Errorf("parsing shutdown grace period %q: %w", periodStr, err)
+	}
+
+	if period <= 0 {
+		s.logger.Errorw("shutdown grace period not positive", "period", periodStr)
+		return 0, fmt.Errorf("shutdown grace period %q not positive", periodStr)
+	}
+
+	return period, nil
+}
+
+// VaultKV returns the location of the Vault secret to read secrets from. This is not
+// part of the Sourcer interface, as it configures a source of config rather than
+// the exporter itself. The address and token are read from the standard Vault
+// environment variables.
+func (s *EnvVarSourcer) VaultKV() (*VaultKVConfig, error) {
+	address, err := s.getEnvVar(vaultAddrEnvVar)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateAbsoluteURL(address); err != nil {
+		s.logger.Errorw("parsing Vault address", "address", address, "error", err)
+		return nil, fmt.Errorf("parsing Vault address %q: %w", address, err)
+	}
+
+	token, err := s.getSecretEnvVar(vaultTokenEnvVar)
+	if err != nil {
+		s.logger.Errorw("Vault address set without Vault token", "error", err)
+		return nil, fmt.Errorf("Vault address set without Vault token: %w", err)
+	}
+
+	path, err := s.getEnvVar(vaultKVPathEnvVar)
+	if err != nil {
+		s.logger.Errorw("Vault address set without Vault KV path", "error", err)
+		return nil, fmt.Errorf("Vault address set without Vault KV path: %w", err)
+	}
+
+	mount, err := s.getEnvVar(vaultKVMountEnvVar)
+	if errors.Is(err, ErrNotSet) {
+		mount = defaultVaultKVMount
+	}
+
+	return &VaultKVConfig{
+		Address: address,
+		Token:   token,
+		Mount:   mount,
+		Path:    path,
+	}, nil
+}
+
+// SecretFilenames returns the names of the files from which secrets are read, as named
+// by the _FILE environment variables. The files are only read when the config is
+// sourced, so the files must be watched for changes to pick up rotated secrets.
+func SecretFilenames() []string {
+	filenames := make([]string, 0)
+	for _, env := range os.Environ() {
+		name, filename, _ := strings.Cut(env, "=")
+		if filename == "" || !strings.HasSuffix(name, fileEnvVarSuffix) {
+			continue
+		}
+
+		if isSecretEnvVar(strings.TrimSuffix(name, fileEnvVarSuffix)) {
+			filenames = append(filenames, filename)
+		}
+	}
+	sort.Strings(filenames)
+
+	return filenames
+}
+
+func isSecretEnvVar(name string) bool {
+	switch name {
+	case apiKeyEnvVar, apiSecretEnvVar, webhookSecretEnvVar, reloadTokenEnvVar, vaultTokenEnvVar:
+		return true
+	}
+
+	return strings.HasPrefix(name, accountEnvVarPrefix) &&
+		(strings.HasSuffix(name, accountAPIKeyEnvVarSuffix) || strings.HasSuffix(name, accountAPISecretEnvVarSuffix))
+}
+
+// getSecretEnvVar gets a secret from an environment variable or, if it is not set, from
+// the file named by the corresponding _FILE environment variable. The file is read each
+// time the config is sourced, i.e. on startup and on each reload.
+func (s *EnvVarSourcer) getSecretEnvVar(name string) (string, error) {
+	val, err := s.getEnvVar(name)
+	if !errors.Is(err, ErrNotSet) {
+		return val, err
+	}
+
+	filename, err := s.getEnvVar(name + fileEnvVarSuffix)
+	if err != nil {
+		return "", err
+	}
+
+	contents, err := os.ReadFile(filename)
+	if err != nil {
+		s.logger.Errorw("reading secret file", "name", name+fileEnvVarSuffix, "filename", filename, "error", err)
+		return "", fmt.Errorf("reading secret file %q: %w", filename, err)
+	}
+
+	// Secret files commonly end with a newline, which is never part of the secret
+	secret := strings.TrimRight(string(contents), "\r\n")
+	if secret == "" {
+		s.logger.Errorw("secret file empty", "name", name+fileEnvVarSuffix, "filename", filename)
+		return "", fmt.Errorf("secret file %q empty", filename)
+	}
+
+	s.logger.Infow("read secret file", "name", name+fileEnvVarSuffix, "filename", filename)
+	return secret, nil
+}
+
+func (s *EnvVarSourcer) getCSVEnvVar(name string) ([]string, error) {
+	csv, err := s.getEnvVar(name)
 	if err != nil {
 		return nil, err
 	}
 
 	split := strings.Split(csv, ",")
-	trimmedNames := make([]string, 0, len(split))
-	for _, name := range split {
-		trimmed := strings.Trim(name, " ")
+	trimmedValues := make([]string, 0, len(split))
+	for _, value := range split {
+		trimmed := strings.Trim(value, " ")
 		if trimmed == "" {
-			s.logger.Errorw("invalid group name in environment variable", "name", groupsEnvVar)
-			return nil, fmt.Errorf("invalid group name in environment variable %q", groupsEnvVar)
+			s.logger.Errorw("invalid value in environment variable", "name", name)
+			return nil, fmt.Errorf("invalid value in environment variable %q", name)
 		}
 
-		trimmedNames = append(trimmedNames, trimmed)
+		trimmedValues = append(trimmedValues, trimmed)
 	}
 
-	return trimmedNames, nil
+	return trimmedValues, nil
 }
 
-func (s *EnvVarSourcer) MetricsPort() (uint16, error) {
-	portStr, err := s.getEnvVar(metricsPortEnvVar)
+func (s *EnvVarSourcer) getBoolEnvVar(name string) (bool, error) {
+	boolStr, err := s.getEnvVar(name)
 	if err != nil {
-		return 0, err
+		return false, err
 	}
 
-	port, err := strconv.ParseUint(portStr, 10, 16)
+	b, err := strconv.ParseBool(boolStr)
 	if err != nil {
-		s.logger.Errorw("parsing metrics port", "port", portStr, "error", err)
-		return 0, fmt.Errorf("parsing metrics port %q: %w", portStr, err)
+		s.logger.Errorw("parsing boolean environment variable", "name", name, "value", boolStr, "error", err)
+		return false, fmt.Errorf("parsing boolean environment variable %q: %w", name, err)
 	}
 
-	return uint16(port), nil
+	return b, nil
 }
 
 func (s *EnvVarSourcer) getEnvVar(name string) (string, error) {
@@ -100,6 +485,35 @@ func (s *EnvVarSourcer) getEnvVar(name string) (string, error) {
 		return val, nil
 	}
 
-	s.logger.Errorw("environment variable not set", "name", name)
-	return "", fmt.Errorf("environment variable %q not set", name)
+	s.logger.Infow("environment variable not set", "name", name)
+	return "", fmt.Errorf("environment variable %q: %w", name, ErrNotSet)
+}
+
+// envVarAccountName converts an account name to the form used in environment variable
+// names, i.e. upper-cased, with any characters other than letters and digits
+// replaced by underscores
+func envVarAccountName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+func validateAbsoluteURL(urlStr string) error {
+	parsed, err := url.Parse(urlStr)
+	if err != nil {
+		return err
+	}
+
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("not an absolute HTTP(S) URL")
+	}
+
+	return nil
 }
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestEnvVarSourcerSecretFileReread(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "api_secret")
+	if err := os.WriteFile(filename, []byte("s3cr3t\n"), 0o600); err != nil {
+		t.Fatalf("writing secret file: %v", err)
+	}
+
+	t.Setenv(apiSecretEnvVar, "")
+	t.Setenv(apiSecretEnvVar+fileEnvVarSuffix, filename)
+
+	sourcer := NewEnvVarSourcer(zap.NewNop().Sugar())
+
+	apiSecret, err := sourcer.APISecret()
+	if err != nil {
+		t.Fatalf("getting API secret: %v", err)
+	}
+
+	if apiSecret != "s3cr3t" {
+		t.Errorf("got API secret %q, want %q", apiSecret, "s3cr3t")
+	}
+
+	// The file is read each time the config is sourced, so a rotated secret is picked
+	// up on the next reload
+	if err := os.WriteFile(filename, []byte("r0tated\n"), 0o600); err != nil {
+		t.Fatalf("rewriting secret file: %v", err)
+	}
+
+	apiSecret, err = sourcer.APISecret()
+	if err != nil {
+		t.Fatalf("getting API secret: %v", err)
+	}
+
+	if apiSecret != "r0tated" {
+		t.Errorf("got API secret %q, want %q", apiSecret, "r0tated")
+	}
+}
+
+func TestSecretFilenames(t *testing.T) {
+	t.Setenv(apiSecretEnvVar+fileEnvVarSuffix, "/run/secrets/api_secret")
+	t.Setenv(groupsEnvVar+fileEnvVarSuffix, "/run/secrets/groups")
+
+	filenames := SecretFilenames()
+
+	found := false
+	for _, filename := range filenames {
+		if filename == "/run/secrets/groups" {
+			t.Errorf("got filename %q of a setting which is not a secret", filename)
+		}
+
+		if filename == "/run/secrets/api_secret" {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Errorf("got filenames %q, want them to include %q", filenames, "/run/secrets/api_secret")
+	}
+}
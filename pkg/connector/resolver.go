@@ -0,0 +1,167 @@
+package connector
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type Resolver interface {
+	// ResolveIDToConnector resolves the ID to the connector, and the name of the account
+	// to which the connector belongs
+	ResolveIDToConnector(id string) (account string, connector *Connector, err error)
+}
+
+// ResolverSource is the connector listers of a single account
+type ResolverSource struct {
+	Account string
+	Listers []Lister
+}
+
+type resolvedConnector struct {
+	account   string
+	connector *Connector
+}
+
+// ListerResolver resolves connector IDs using the connectors listed by the listers of
+// all accounts. The listed connectors are cached, and only re-listed when an unknown
+// ID is resolved. Events are delivered for connectors which are not collected too, so
+// the connectors are re-listed at most once per minimum re-list interval, and unknown
+// IDs are not resolved in between. The connectors are listed without holding the lock,
+// so resolving known IDs is not blocked by a re-list, and concurrent resolutions of
+// unknown IDs wait for the single re-list in progress rather than starting their own.
+type ListerResolver struct {
+	Sources           []*ResolverSource
+	MinRelistInterval time.Duration
+
+	lock       *sync.Mutex
+	connectors map[string]*resolvedConnector // Keyed by connector ID
+	lastListed time.Time
+	listing    chan struct{} // Closed when the re-list in progress completes, nil if none is
+	generation int           // Incremented when the sources are replaced
+	logger     *zap.SugaredLogger
+}
+
+func NewListerResolver(logger *zap.SugaredLogger,
+	sources []*ResolverSource,
+	minRelistInterval time.Duration) *ListerResolver {
+	logger = getComponentLogger(logger, "lister_resolver")
+
+	return &ListerResolver{
+		Sources:           sources,
+		MinRelistInterval: minRelistInterval,
+		lock:              new(sync.Mutex),
+		connectors:        make(map[string]*resolvedConnector),
+		logger:            logger,
+	}
+}
+
+// SetSources replaces the sources, e.g. when the config is reloaded. The cache is
+// cleared, so that connectors of groups which are no longer listed are not resolved.
+func (r *ListerResolver) SetSources(sources []*ResolverSource) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.Sources = sources
+	r.connectors = make(map[string]*resolvedConnector)
+	r.lastListed = time.Time{}
+	r.generation++
+}
+
+func (r *ListerResolver) ResolveIDToConnector(id string) (string, *Connector, error) {
+	r.lock.Lock()
+
+	if resolved, ok := r.connectors[id]; ok {
+		r.lock.Unlock()
+		return resolved.account, resolved.connector, nil
+	}
+
+	// The connector may be listed by the re-list in progress, so wait for it and
+	// look again rather than re-listing again
+	if listing := r.listing; listing != nil {
+		r.lock.Unlock()
+		<-listing
+		return r.lookUp(id)
+	}
+
+	// Cache miss, the connector may have been created since we last listed
+	if lastListed := r.lastListed; time.Since(lastListed) < r.MinRelistInterval {
+		r.lock.Unlock()
+		r.logger.Infow("unknown connector ID, not re-listing connectors",
+			"id", id,
+			"last_listed", lastListed)
+		return "", nil, fmt.Errorf("no entry for connector ID %q", id)
+	}
+	// Failed listings are rate-limited too, so the time of the attempt is recorded
+	r.lastListed = time.Now()
+
+	listing := make(chan struct{})
+	r.listing = listing
+	sources := r.Sources
+	generation := r.generation
+	r.lock.Unlock()
+
+	connectors, err := r.list(id, sources)
+
+	r.lock.Lock()
+	// The listed connectors are discarded if the sources were replaced while listing,
+	// as they may include connectors of groups which are no longer listed
+	if err == nil && r.generation == generation {
+		r.connectors = connectors
+	}
+	r.listing = nil
+	close(listing)
+	r.lock.Unlock()
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return r.lookUp(id)
+}
+
+// list lists the connectors of all of the sources, without holding the lock
+func (r *ListerResolver) list(id string, sources []*ResolverSource) (map[string]*resolvedConnector, error) {
+	connectors := make(map[string]*resolvedConnector)
+	for _, source := range sources {
+		for _, lister := range source.Listers {
+			listed, err := lister.List()
+			if err != nil {
+				r.logger.Errorw("listing connectors",
+					"id", id,
+					"account", source.Account,
+					"group_name", lister.GetGroupName(),
+					"error", err)
+				return nil, fmt.Errorf("listing connectors for connector ID %q: %w", id, err)
+			}
+
+			for _, connector := range listed {
+				connectors[connector.ID] = &resolvedConnector{source.Account, connector}
+			}
+		}
+	}
+
+	return connectors, nil
+}
+
+// lookUp resolves the ID using the connectors listed by the last re-list
+func (r *ListerResolver) lookUp(id string) (string, *Connector, error) {
+	r.lock.Lock()
+	resolved, ok := r.connectors[id]
+	r.lock.Unlock()
+
+	if ok {
+		r.logger.Infow("resolved connector ID",
+			"id", id,
+			"account", resolved.account,
+			"name", resolved.connector.Name,
+			"group_name", resolved.connector.GroupName)
+		return resolved.account, resolved.connector, nil
+	}
+
+	// If we get here, there was no connector with an ID matching that provided
+	r.logger.Errorw("no entry for connector ID", "id", id)
+	return "", nil, fmt.Errorf("no entry for connector ID %q", id)
+}
@@ -0,0 +1,97 @@
+package destination
+
+import (
+	"fmt"
+
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp/destination"
+	"go.uber.org/zap"
+)
+
+// convertDestination converts a destination returned by the API into a Destination
+// belonging to the given group
+func convertDestination(logger *zap.SugaredLogger,
+	data *apiresp.Data,
+	groupID, groupName string) (*Destination, error) {
+	id := data.ID
+	name := groupName // XXX: There is no way to get this info directly from the destination
+	// as Fivetran does not have the concept of a group name separate from the destination name.
+	// Instead, we imply this from the name of the group.
+
+	setupStatus, err := convertSetupStatus(logger, data.SetupStatus)
+	if err != nil {
+		logger.Errorw("converting Setup Status",
+			"id", id,
+			"name", name,
+			"group_id", groupID,
+			"group_name", groupName,
+			"setup_status", data.SetupStatus,
+			"error", err)
+		return nil, fmt.Errorf("converting Setup Status: %w", err)
+	}
+
+	setupTests := make([]*SetupTest, 0, len(data.SetupTests))
+	for _, apiSetupTest := range data.SetupTests {
+		setupTestStatus := convertSetupTestStatus(logger, apiSetupTest.Status)
+		setupTests = append(setupTests, &SetupTest{
+			Title:  apiSetupTest.Title,
+			Status: setupTestStatus,
+		})
+	}
+
+	// The local processing agent is the predecessor of the hybrid deployment agent,
+	// so at most one of them is expected to be set
+	agentID := data.HybridDeploymentAgentID
+	if agentID == "" {
+		agentID = data.LocalProcessingAgentID
+	}
+
+	return &Destination{
+		ID:                        id,
+		Name:                      name,
+		GroupID:                   groupID,
+		GroupName:                 groupName,
+		Service:                   data.Service,
+		Region:                    data.Region,
+		TimeZoneOffset:            data.TimeZoneOffset,
+		NetworkingMethod:          data.NetworkingMethod,
+		AgentID:                   agentID,
+		DaylightSavingTimeEnabled: data.DaylightSavingTimeEnabled,
+		SetupStatus:               setupStatus,
+		SetupTests:                setupTests,
+	}, nil
+}
+
+func convertSetupStatus(logger *zap.SugaredLogger, apiSetupStatus apiresp.SetupStatus) (SetupStatus, error) {
+	switch apiSetupStatus {
+	case apiresp.SetupStatusIncomplete:
+		return SetupStatusIncomplete, nil
+	case apiresp.SetupStatusBroken:
+		return SetupStatusBroken, nil
+	case apiresp.SetupStatusConnected:
+		return SetupStatusConnected, nil
+	default:
+		logger.Errorw("illegal API Setup Status", "setup_status", apiSetupStatus)
+		return SetupStatus(""), fmt.Errorf("illegal API Setup Status: %q", apiSetupStatus)
+	}
+}
+
+// convertSetupTestStatus converts a setup test status returned by the API. A status
+// which is not recognised is converted to SetupTestStatusUnknown, so that one
+// unrecognised setup test does not prevent the rest of the destination being reported.
+func convertSetupTestStatus(logger *zap.SugaredLogger, apiSetupTestStatus apiresp.SetupTestStatus) SetupTestStatus {
+	switch apiSetupTestStatus {
+	case apiresp.SetupTestStatusPassed:
+		return SetupTestStatusPassed
+	case apiresp.SetupTestStatusWarning:
+		return SetupTestStatusWarning
+	case apiresp.SetupTestStatusSkipped:
+		return SetupTestStatusSkipped
+	case apiresp.SetupTestStatusFailed:
+		return SetupTestStatusFailed
+	case apiresp.SetupTestStatusJobFailed:
+		return SetupTestStatusJobFailed
+	default:
+		logger.Warnw("unknown API Setup Test Status", "setup_test_status", apiSetupTestStatus)
+		return SetupTestStatusUnknown
+	}
+}
@@ -62,39 +62,17 @@ func (d *APIDescriber) Describe() (*Destination, error) {
 		return nil, fmt.Errorf("getting JSON HTTP response: %w", err)
 	}
 
-	id := describeDestinationResp.Data.ID
-	name := d.GroupName // XXX: There is no way to get this info directly from the destination
-	// as Fivetran does not have the concept of a group name separate from the destination name.
-	// Instead, we imply this from the name of the group.
-	groupID := d.GroupID
-	groupName := d.GroupName
-
-	setupStatus, err := d.convertSetupStatus(describeDestinationResp.Data.SetupStatus)
+	destination, err := convertDestination(d.logger, &describeDestinationResp.Data, d.GroupID, d.GroupName)
 	if err != nil {
-		d.logger.Errorw("converting Setup Status",
-			"id", id,
-			"name", name,
-			"group_id", groupID,
-			"group_name", groupName,
-			"setup_status", describeDestinationResp.Data.SetupStatus,
-			"error", err)
-		return nil, fmt.Errorf("converting Setup Status: %w", err)
-	}
-
-	destination := &Destination{
-		ID:          id,
-		Name:        name,
-		GroupID:     groupID,
-		GroupName:   groupName,
-		Service:     describeDestinationResp.Data.Service,
-		SetupStatus: setupStatus,
+		d.logger.Errorw("converting destination", "group_name", d.GroupName, "error", err)
+		return nil, fmt.Errorf("converting destination: %w", err)
 	}
 
 	d.logger.Infow("discovered destination",
-		"id", id,
-		"name", name,
-		"group_id", groupID,
-		"group_name", groupName)
+		"id", destination.ID,
+		"name", destination.Name,
+		"group_id", destination.GroupID,
+		"group_name", destination.GroupName)
 	return destination, nil
 }
 
@@ -105,17 +83,3 @@ func (d *APIDescriber) GetGroupID() string {
 func (d *APIDescriber) GetGroupName() string {
 	return d.GroupName
 }
-
-func (d *APIDescriber) convertSetupStatus(apiSetupStatus apiresp.SetupStatus) (SetupStatus, error) {
-	switch apiSetupStatus {
-	case apiresp.SetupStatusIncomplete:
-		return SetupStatusIncomplete, nil
-	case apiresp.SetupStatusBroken:
-		return SetupStatusBroken, nil
-	case apiresp.SetupStatusConnected:
-		return SetupStatusConnected, nil
-	default:
-		d.logger.Errorw("illegal API Setup Status", "setup_status", apiSetupStatus)
-		return SetupStatus(""), fmt.Errorf("illegal API Setup Status: %q", apiSetupStatus)
-	}
-}
@@ -1,12 +1,18 @@
 package destination
 
 type Destination struct {
-	ID          string
-	Name        string
-	GroupID     string
-	GroupName   string
-	Service     string
-	SetupStatus SetupStatus
+	ID                        string
+	Name                      string
+	GroupID                   string
+	GroupName                 string
+	Service                   string
+	Region                    string
+	TimeZoneOffset            string
+	NetworkingMethod          string
+	AgentID                   string // Hybrid deployment (or legacy local processing) agent, if any
+	DaylightSavingTimeEnabled bool
+	SetupStatus               SetupStatus
+	SetupTests                []*SetupTest
 }
 
 type SetupStatus string
@@ -16,3 +22,19 @@ const (
 	SetupStatusConnected  SetupStatus = "connected"
 	SetupStatusIncomplete SetupStatus = "incomplete"
 )
+
+type SetupTest struct {
+	Title  string
+	Status SetupTestStatus
+}
+
+type SetupTestStatus string
+
+const (
+	SetupTestStatusPassed    SetupTestStatus = "passed"
+	SetupTestStatusWarning   SetupTestStatus = "warning"
+	SetupTestStatusSkipped   SetupTestStatus = "skipped"
+	SetupTestStatusFailed    SetupTestStatus = "failed"
+	SetupTestStatusJobFailed SetupTestStatus = "job_failed"
+	SetupTestStatusUnknown   SetupTestStatus = "unknown"
+)
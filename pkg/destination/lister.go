@@ -0,0 +1,111 @@
+package destination
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	jsonhttp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/jsonhttp"
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp/destination"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/group"
+	"go.uber.org/zap"
+)
+
+type Lister interface {
+	List() ([]*Destination, error)
+}
+
+// APILister discovers all destinations visible to the API key, rather than describing
+// the destination of a single configured group. The destinations API does not return
+// group names, so the groups are listed to resolve them and to apply the group filter.
+type APILister struct {
+	GroupLister group.Lister
+	GroupFilter group.Filter
+
+	logger       *zap.SugaredLogger
+	unmarshaller *jsonhttp.PaginatedJSONHTTPUnmarshaller[*apiresp.ListDestinationsResp, apiresp.Data]
+}
+
+func NewAPILister(logger *zap.SugaredLogger,
+	APIKey, APISecret, APIURL string,
+	groupLister group.Lister,
+	groupFilter group.Filter,
+	timeout time.Duration) (*APILister, error) {
+	logger = getComponentLogger(logger, "api-lister")
+
+	url, err := url.Parse(APIURL + "/v1/destinations")
+	if err != nil {
+		logger.Errorw("parsing API URL", "url", APIURL, "error", err)
+		return nil, fmt.Errorf("parsing API URL %q: %w", APIURL, err)
+	}
+
+	apiToken := base64.StdEncoding.EncodeToString([]byte(APIKey + ":" + APISecret))
+	httpClient := &http.Client{
+		Timeout: timeout,
+	}
+
+	unmarshaller := jsonhttp.NewPaginatedJSONHTTPUnmarshaller[*apiresp.ListDestinationsResp, apiresp.Data](logger,
+		url,
+		apiToken,
+		httpClient)
+
+	return &APILister{
+		GroupLister:  groupLister,
+		GroupFilter:  groupFilter,
+		logger:       logger,
+		unmarshaller: unmarshaller,
+	}, nil
+}
+
+func (l *APILister) List() ([]*Destination, error) {
+	groups, err := l.GroupLister.List()
+	if err != nil {
+		l.logger.Errorw("listing groups", "error", err)
+		return nil, fmt.Errorf("listing groups: %w", err)
+	}
+
+	groupNames := make(map[string]string, len(groups)) // Keyed by group ID
+	for _, group := range groups {
+		groupNames[group.ID] = group.Name
+	}
+
+	items, err := l.unmarshaller.UnmarshallAllPagesFromHTTPGet()
+	if err != nil {
+		l.logger.Errorw("getting JSON HTTP responses", "error", err)
+		return nil, fmt.Errorf("getting JSON HTTP responses: %w", err)
+	}
+
+	destinations := make([]*Destination, 0, len(items))
+	for i := range items {
+		item := &items[i]
+
+		groupName, ok := groupNames[item.GroupID]
+		if !ok {
+			// The group may have been created after we listed the groups
+			l.logger.Warnw("no entry for destination group ID", "id", item.ID, "group_id", item.GroupID)
+			continue
+		}
+
+		if !l.GroupFilter.Include(groupName) {
+			continue
+		}
+
+		destination, err := convertDestination(l.logger, item, item.GroupID, groupName)
+		if err != nil {
+			l.logger.Errorw("converting destination", "group_name", groupName, "error", err)
+			return nil, fmt.Errorf("converting destination: %w", err)
+		}
+
+		l.logger.Infow("discovered destination",
+			"id", destination.ID,
+			"name", destination.Name,
+			"group_id", destination.GroupID,
+			"group_name", destination.GroupName)
+		destinations = append(destinations, destination)
+	}
+
+	l.logger.Infow("listed destinations from API", "count", len(destinations))
+	return destinations, nil
+}
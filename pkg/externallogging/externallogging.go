@@ -0,0 +1,9 @@
+package externallogging
+
+// LogService is an external logging service to which the logs of a group are sent
+type LogService struct {
+	ID      string
+	GroupID string
+	Service string
+	Enabled bool
+}
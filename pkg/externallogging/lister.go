@@ -0,0 +1,77 @@
+package externallogging
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	jsonhttp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/jsonhttp"
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp/externallogging"
+	"go.uber.org/zap"
+)
+
+type Lister interface {
+	List() ([]*LogService, error)
+}
+
+// APILister lists all external logging services visible to the API key
+type APILister struct {
+	logger       *zap.SugaredLogger
+	unmarshaller *jsonhttp.PaginatedJSONHTTPUnmarshaller[*apiresp.ListLogServicesResp, apiresp.ListLogServicesRespDataItem]
+}
+
+func NewAPILister(logger *zap.SugaredLogger,
+	APIKey, APISecret, APIURL string,
+	timeout time.Duration) (*APILister, error) {
+	logger = getComponentLogger(logger, "api-lister")
+
+	url, err := url.Parse(APIURL + "/v1/external-logging")
+	if err != nil {
+		logger.Errorw("parsing API URL", "url", APIURL, "error", err)
+		return nil, fmt.Errorf("parsing API URL %q: %w", APIURL, err)
+	}
+
+	apiToken := base64.StdEncoding.EncodeToString([]byte(APIKey + ":" + APISecret))
+	httpClient := &http.Client{
+		Timeout: timeout,
+	}
+
+	unmarshaller := jsonhttp.NewPaginatedJSONHTTPUnmarshaller[*apiresp.ListLogServicesResp, apiresp.ListLogServicesRespDataItem](logger,
+		url,
+		apiToken,
+		httpClient)
+
+	return &APILister{
+		logger:       logger,
+		unmarshaller: unmarshaller,
+	}, nil
+}
+
+func (l *APILister) List() ([]*LogService, error) {
+	items, err := l.unmarshaller.UnmarshallAllPagesFromHTTPGet()
+	if err != nil {
+		l.logger.Errorw("getting JSON HTTP responses", "error", err)
+		return nil, fmt.Errorf("getting JSON HTTP responses: %w", err)
+	}
+
+	logServices := make([]*LogService, 0, len(items))
+	for _, item := range items {
+		logService := &LogService{
+			ID:      item.ID,
+			GroupID: item.GroupID,
+			Service: item.Service,
+			Enabled: item.Enabled,
+		}
+
+		l.logger.Infow("discovered external logging service",
+			"id", logService.ID,
+			"group_id", logService.GroupID,
+			"service", logService.Service)
+		logServices = append(logServices, logService)
+	}
+
+	l.logger.Infow("listed external logging services from API", "count", len(logServices))
+	return logServices, nil
+}
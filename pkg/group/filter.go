@@ -0,0 +1,65 @@
+package group
+
+import (
+	"fmt"
+	"regexp"
+
+	"go.uber.org/zap"
+)
+
+type Filter interface {
+	Include(groupName string) bool
+}
+
+// RegexpFilter includes groups whose name matches the include pattern and does not
+// match the exclude pattern. Patterns are anchored to the whole group name, and a
+// nil pattern is ignored.
+type RegexpFilter struct {
+	IncludePattern *regexp.Regexp
+	ExcludePattern *regexp.Regexp
+	logger         *zap.SugaredLogger
+}
+
+func NewRegexpFilter(logger *zap.SugaredLogger, includePattern, excludePattern string) (*RegexpFilter, error) {
+	logger = getComponentLogger(logger, "regexp_filter")
+
+	include, err := compileAnchored(includePattern)
+	if err != nil {
+		logger.Errorw("compiling include pattern", "pattern", includePattern, "error", err)
+		return nil, fmt.Errorf("compiling include pattern %q: %w", includePattern, err)
+	}
+
+	exclude, err := compileAnchored(excludePattern)
+	if err != nil {
+		logger.Errorw("compiling exclude pattern", "pattern", excludePattern, "error", err)
+		return nil, fmt.Errorf("compiling exclude pattern %q: %w", excludePattern, err)
+	}
+
+	return &RegexpFilter{
+		IncludePattern: include,
+		ExcludePattern: exclude,
+		logger:         logger,
+	}, nil
+}
+
+func (f *RegexpFilter) Include(groupName string) bool {
+	if f.IncludePattern != nil && !f.IncludePattern.MatchString(groupName) {
+		f.logger.Debugw("group not matched by include pattern", "group_name", groupName)
+		return false
+	}
+
+	if f.ExcludePattern != nil && f.ExcludePattern.MatchString(groupName) {
+		f.logger.Debugw("group matched by exclude pattern", "group_name", groupName)
+		return false
+	}
+
+	return true
+}
+
+func compileAnchored(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
@@ -0,0 +1,110 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/jsonhttp"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/refresh"
+	"go.uber.org/zap"
+)
+
+// Healthy reports that the process is alive and serving requests
+func Healthy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodHead)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	fmt.Fprintln(w, "Healthy")
+}
+
+// ReadySource is the refresh state reporter of a single account
+type ReadySource struct {
+	Account  string
+	Reporter refresh.Reporter
+}
+
+// ReadyHandler reports whether every group of every account has been refreshed
+// successfully within the window, and that the API credentials of no account have been
+// rejected since. The refresh state is that recorded by the collectors, so checking
+// readiness does not make any calls to the API.
+type ReadyHandler struct {
+	Sources []*ReadySource
+
+	lock   *sync.RWMutex
+	window time.Duration
+	logger *zap.SugaredLogger
+}
+
+func NewReadyHandler(logger *zap.SugaredLogger, sources []*ReadySource, window time.Duration) *ReadyHandler {
+	logger = getComponentLogger(logger, "ready-handler")
+
+	return &ReadyHandler{
+		Sources: sources,
+		lock:    new(sync.RWMutex),
+		window:  window,
+		logger:  logger,
+	}
+}
+
+// SetWindow atomically replaces the window, e.g. when the config is reloaded
+func (h *ReadyHandler) SetWindow(window time.Duration) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.window = window
+}
+
+func (h *ReadyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodHead)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.lock.RLock()
+	window := h.window
+	h.lock.RUnlock()
+
+	// The endpoint is served without authentication, so the reasons, which name the
+	// accounts and groups, are only logged, and the response only counts the groups
+	// which are not ready
+	reasons := make([]string, 0)
+	for _, source := range h.Sources {
+		for _, state := range source.Reporter.RefreshStates() {
+			if reason := notReadyReason(state, window); reason != "" {
+				reasons = append(reasons, fmt.Sprintf("account %q: group %q: %s", source.Account, state.GroupName, reason))
+			}
+		}
+	}
+
+	if len(reasons) != 0 {
+		h.logger.Warnw("not ready", "reasons", reasons)
+		http.Error(w, fmt.Sprintf("Not ready: %d groups not ready", len(reasons)), http.StatusServiceUnavailable)
+		return
+	}
+
+	fmt.Fprintln(w, "Ready")
+}
+
+// notReadyReason returns why the group is not ready, or the empty string if it is ready
+func notReadyReason(state *refresh.State, window time.Duration) string {
+	if errors.Is(state.LastError, jsonhttp.ErrUnauthorized) && state.LastErrorTime.After(state.LastSuccess) {
+		return "API credentials rejected"
+	}
+
+	if state.LastSuccess.IsZero() {
+		return "never refreshed"
+	}
+
+	if time.Since(state.LastSuccess) > window {
+		return fmt.Sprintf("not refreshed within %s", window)
+	}
+
+	return ""
+}
@@ -0,0 +1,281 @@
+package inventory
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/connector"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/destination"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/group"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/refresh"
+	"go.uber.org/zap"
+)
+
+// PathPrefix is the path under which the handler serves the API
+const PathPrefix = "/api/v1/"
+
+// ConnectorCollector is the collector of the connectors of an account, which records
+// the connectors and the outcome of each listing of the connectors of a group
+type ConnectorCollector interface {
+	refresh.Reporter
+	Connectors() []*connector.Connector
+}
+
+// DestinationCollector is the collector of the destinations of an account, which
+// records the destinations
+type DestinationCollector interface {
+	Destinations() []*destination.Destination
+}
+
+// Source is the collected groups and collectors of a single account
+type Source struct {
+	Account              string
+	Groups               []*group.Group
+	ConnectorCollector   ConnectorCollector
+	DestinationCollector DestinationCollector
+}
+
+type groupsResp struct {
+	Groups []*respGroup `json:"groups"`
+}
+
+type respGroup struct {
+	Account       string     `json:"account"`
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	LastRefresh   *time.Time `json:"last_refresh"`    // Null if never refreshed
+	LastError     *string    `json:"last_error"`      // Null if the last refresh was successful
+	LastErrorTime *time.Time `json:"last_error_time"` // Null if never failed to refresh
+}
+
+type connectorsResp struct {
+	Connectors []*respConnector `json:"connectors"`
+}
+
+type respConnector struct {
+	Account           string `json:"account"`
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	GroupID           string `json:"group_id"`
+	GroupName         string `json:"group_name"`
+	Service           string `json:"service"`
+	Paused            bool   `json:"paused"`
+	IsHistoricalSync  bool   `json:"is_historical_sync"`
+	SyncFrequencyMins int    `json:"sync_frequency_mins"`
+	TaskCount         int    `json:"task_count"`
+	WarningCount      int    `json:"warning_count"`
+	SetupState        string `json:"setup_state"`
+	SyncState         string `json:"sync_state"`
+	UpdateState       string `json:"update_state"`
+}
+
+type destinationsResp struct {
+	Destinations []*respDestination `json:"destinations"`
+}
+
+type respDestination struct {
+	Account                   string               `json:"account"`
+	ID                        string               `json:"id"`
+	Name                      string               `json:"name"`
+	GroupID                   string               `json:"group_id"`
+	GroupName                 string               `json:"group_name"`
+	Service                   string               `json:"service"`
+	Region                    string               `json:"region"`
+	TimeZoneOffset            string               `json:"time_zone_offset"`
+	NetworkingMethod          string               `json:"networking_method"`
+	AgentID                   string               `json:"agent_id"`
+	DaylightSavingTimeEnabled bool                 `json:"daylight_saving_time_enabled"`
+	SetupStatus               string               `json:"setup_status"`
+	SetupTests                []*respDestSetupTest `json:"setup_tests"`
+}
+
+type respDestSetupTest struct {
+	Title  string `json:"title"`
+	Status string `json:"status"`
+}
+
+// Handler serves a read-only JSON API of the groups, connectors and destinations of
+// all accounts. The resources are those recorded by the collectors on the most recent
+// scrape, so serving the API does not make any calls to the Fivetran API.
+//
+// The connectors may be filtered by the "group" (name or ID), "state" (setup, sync or
+// update state) and "service" query parameters, and the destinations by the "group"
+// query parameter.
+type Handler struct {
+	Sources []*Source
+
+	lock   *sync.RWMutex
+	mux    *http.ServeMux
+	logger *zap.SugaredLogger
+}
+
+func NewHandler(logger *zap.SugaredLogger, sources []*Source) *Handler {
+	logger = getComponentLogger(logger, "handler")
+
+	handler := &Handler{
+		Sources: sources,
+		lock:    new(sync.RWMutex),
+		mux:     http.NewServeMux(),
+		logger:  logger,
+	}
+	handler.mux.HandleFunc(PathPrefix+"groups", handler.serveGroups)
+	handler.mux.HandleFunc(PathPrefix+"connectors", handler.serveConnectors)
+	handler.mux.HandleFunc(PathPrefix+"destinations", handler.serveDestinations)
+
+	return handler
+}
+
+// SetSources atomically replaces the sources, e.g. when the config is reloaded
+func (h *Handler) SetSources(sources []*Source) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.Sources = sources
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) serveGroups(w http.ResponseWriter, r *http.Request) {
+	resp := &groupsResp{Groups: make([]*respGroup, 0)}
+	for _, source := range h.getSources() {
+		states := make(map[string]*refresh.State)
+		for _, state := range source.ConnectorCollector.RefreshStates() {
+			states[state.GroupName] = state
+		}
+
+		for _, g := range source.Groups {
+			respGroup := &respGroup{
+				Account: source.Account,
+				ID:      g.ID,
+				Name:    g.Name,
+			}
+
+			if state, ok := states[g.Name]; ok {
+				if !state.LastSuccess.IsZero() {
+					respGroup.LastRefresh = &state.LastSuccess
+				}
+				if state.LastError != nil {
+					lastError := state.LastError.Error()
+					respGroup.LastError = &lastError
+				}
+				if !state.LastErrorTime.IsZero() {
+					respGroup.LastErrorTime = &state.LastErrorTime
+				}
+			}
+
+			resp.Groups = append(resp.Groups, respGroup)
+		}
+	}
+
+	h.writeResp(w, resp)
+}
+
+func (h *Handler) serveConnectors(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	groupFilter := query.Get("group")
+	stateFilter := query.Get("state")
+	serviceFilter := query.Get("service")
+
+	resp := &connectorsResp{Connectors: make([]*respConnector, 0)}
+	for _, source := range h.getSources() {
+		for _, conn := range source.ConnectorCollector.Connectors() {
+			if groupFilter != "" && groupFilter != conn.GroupName && groupFilter != conn.GroupID {
+				continue
+			}
+
+			// The setup, sync and update states have no values in common
+			if stateFilter != "" &&
+				stateFilter != string(conn.SetupState) &&
+				stateFilter != string(conn.SyncState) &&
+				stateFilter != string(conn.UpdateState) {
+				continue
+			}
+
+			if serviceFilter != "" && serviceFilter != conn.Service {
+				continue
+			}
+
+			resp.Connectors = append(resp.Connectors, &respConnector{
+				Account:           source.Account,
+				ID:                conn.ID,
+				Name:              conn.Name,
+				GroupID:           conn.GroupID,
+				GroupName:         conn.GroupName,
+				Service:           conn.Service,
+				Paused:            conn.Paused,
+				IsHistoricalSync:  conn.IsHistoricalSync,
+				SyncFrequencyMins: conn.SyncFrequencyMins,
+				TaskCount:         conn.TaskCount,
+				WarningCount:      conn.WarningCount,
+				SetupState:        string(conn.SetupState),
+				SyncState:         string(conn.SyncState),
+				UpdateState:       string(conn.UpdateState),
+			})
+		}
+	}
+
+	h.writeResp(w, resp)
+}
+
+func (h *Handler) serveDestinations(w http.ResponseWriter, r *http.Request) {
+	groupFilter := r.URL.Query().Get("group")
+
+	resp := &destinationsResp{Destinations: make([]*respDestination, 0)}
+	for _, source := range h.getSources() {
+		for _, dest := range source.DestinationCollector.Destinations() {
+			if groupFilter != "" && groupFilter != dest.GroupName && groupFilter != dest.GroupID {
+				continue
+			}
+
+			setupTests := make([]*respDestSetupTest, 0, len(dest.SetupTests))
+			for _, setupTest := range dest.SetupTests {
+				setupTests = append(setupTests, &respDestSetupTest{
+					Title:  setupTest.Title,
+					Status: string(setupTest.Status),
+				})
+			}
+
+			resp.Destinations = append(resp.Destinations, &respDestination{
+				Account:                   source.Account,
+				ID:                        dest.ID,
+				Name:                      dest.Name,
+				GroupID:                   dest.GroupID,
+				GroupName:                 dest.GroupName,
+				Service:                   dest.Service,
+				Region:                    dest.Region,
+				TimeZoneOffset:            dest.TimeZoneOffset,
+				NetworkingMethod:          dest.NetworkingMethod,
+				AgentID:                   dest.AgentID,
+				DaylightSavingTimeEnabled: dest.DaylightSavingTimeEnabled,
+				SetupStatus:               string(dest.SetupStatus),
+				SetupTests:                setupTests,
+			})
+		}
+	}
+
+	h.writeResp(w, resp)
+}
+
+func (h *Handler) getSources() []*Source {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	return h.Sources
+}
+
+func (h *Handler) writeResp(w http.ResponseWriter, resp any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Errorw("encoding response", "error", err)
+	}
+}
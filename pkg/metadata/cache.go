@@ -0,0 +1,117 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type Looker interface {
+	// LookUp returns the connector type of the service, and false if it is not known
+	LookUp(service string) (*ConnectorType, bool)
+}
+
+// Cache maps services to their connector types. The metadata rarely changes, so it is
+// refreshed in the background on a slow schedule. If a refresh fails, the previously
+// cached metadata continues to be served; until the first successful refresh, no
+// services are known. Failed refreshes are retried after the retry interval, which is
+// doubled on each consecutive failure up to the maximum retry interval.
+type Cache struct {
+	Lister           Lister
+	RefreshInterval  time.Duration
+	RetryInterval    time.Duration
+	MaxRetryInterval time.Duration
+
+	lock           *sync.RWMutex
+	connectorTypes map[string]*ConnectorType // Keyed by service
+	logger         *zap.SugaredLogger
+}
+
+func NewCache(logger *zap.SugaredLogger,
+	lister Lister,
+	refreshInterval, retryInterval, maxRetryInterval time.Duration) *Cache {
+	logger = getComponentLogger(logger, "cache")
+
+	return &Cache{
+		Lister:           lister,
+		RefreshInterval:  refreshInterval,
+		RetryInterval:    retryInterval,
+		MaxRetryInterval: maxRetryInterval,
+		lock:             new(sync.RWMutex),
+		connectorTypes:   make(map[string]*ConnectorType),
+		logger:           logger,
+	}
+}
+
+// Run refreshes the cache immediately, and then every refresh interval until the
+// context is cancelled, retrying failed refreshes with backoff
+func (c *Cache) Run(ctx context.Context) {
+	retryInterval := c.RetryInterval
+	for {
+		interval := c.RefreshInterval
+		if err := c.refresh(); err != nil {
+			interval = retryInterval
+			c.logger.Infow("retrying connector type cache refresh", "retry_interval", interval)
+
+			retryInterval *= 2
+			if retryInterval > c.MaxRetryInterval {
+				retryInterval = c.MaxRetryInterval
+			}
+		} else {
+			retryInterval = c.RetryInterval
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			c.logger.Infow("stopping cache refresh")
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Cache) LookUp(service string) (*ConnectorType, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	connectorType, ok := c.connectorTypes[service]
+	return connectorType, ok
+}
+
+// SetLister replaces the lister, e.g. when the config is reloaded. The cached metadata
+// continues to be served until the next refresh.
+func (c *Cache) SetLister(lister Lister) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.Lister = lister
+}
+
+func (c *Cache) refresh() error {
+	c.lock.RLock()
+	lister := c.Lister
+	c.lock.RUnlock()
+
+	connectorTypes, err := lister.List()
+	if err != nil {
+		c.logger.Errorw("refreshing connector type cache", "error", err)
+		return fmt.Errorf("refreshing connector type cache: %w", err)
+	}
+
+	connectorTypesMap := make(map[string]*ConnectorType, len(connectorTypes))
+	for _, connectorType := range connectorTypes {
+		connectorTypesMap[connectorType.ID] = connectorType
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.connectorTypes = connectorTypesMap
+
+	c.logger.Infow("refreshed connector type cache", "count", len(connectorTypes))
+	return nil
+}
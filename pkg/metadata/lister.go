@@ -0,0 +1,70 @@
+package metadata
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	jsonhttp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/jsonhttp"
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp/metadata"
+	"go.uber.org/zap"
+)
+
+type Lister interface {
+	List() ([]*ConnectorType, error)
+}
+
+// APILister lists the metadata of all connector types supported by Fivetran
+type APILister struct {
+	logger       *zap.SugaredLogger
+	unmarshaller *jsonhttp.PaginatedJSONHTTPUnmarshaller[*apiresp.ListConnectorTypesResp, apiresp.ListConnectorTypesRespDataItem]
+}
+
+func NewAPILister(logger *zap.SugaredLogger,
+	APIKey, APISecret, APIURL string,
+	timeout time.Duration) (*APILister, error) {
+	logger = getComponentLogger(logger, "api-lister")
+
+	url, err := url.Parse(APIURL + "/v1/metadata/connector-types")
+	if err != nil {
+		logger.Errorw("parsing API URL", "url", APIURL, "error", err)
+		return nil, fmt.Errorf("parsing API URL %q: %w", APIURL, err)
+	}
+
+	apiToken := base64.StdEncoding.EncodeToString([]byte(APIKey + ":" + APISecret))
+	httpClient := &http.Client{
+		Timeout: timeout,
+	}
+
+	unmarshaller := jsonhttp.NewPaginatedJSONHTTPUnmarshaller[*apiresp.ListConnectorTypesResp, apiresp.ListConnectorTypesRespDataItem](logger,
+		url,
+		apiToken,
+		httpClient)
+
+	return &APILister{
+		logger:       logger,
+		unmarshaller: unmarshaller,
+	}, nil
+}
+
+func (l *APILister) List() ([]*ConnectorType, error) {
+	items, err := l.unmarshaller.UnmarshallAllPagesFromHTTPGet()
+	if err != nil {
+		l.logger.Errorw("getting JSON HTTP responses", "error", err)
+		return nil, fmt.Errorf("getting JSON HTTP responses: %w", err)
+	}
+
+	connectorTypes := make([]*ConnectorType, 0, len(items))
+	for _, item := range items {
+		connectorTypes = append(connectorTypes, &ConnectorType{
+			ID:   item.ID,
+			Name: item.Name,
+			Type: item.Type,
+		})
+	}
+
+	l.logger.Infow("listed connector types from API", "count", len(connectorTypes))
+	return connectorTypes, nil
+}
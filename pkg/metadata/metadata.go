@@ -0,0 +1,9 @@
+package metadata
+
+// ConnectorType describes a service, as used in the service field of connectors
+// and destinations
+type ConnectorType struct {
+	ID   string
+	Name string
+	Type string
+}
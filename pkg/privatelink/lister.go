@@ -0,0 +1,115 @@
+package privatelink
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	jsonhttp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/jsonhttp"
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp/privatelink"
+	"go.uber.org/zap"
+)
+
+type Lister interface {
+	List() ([]*PrivateLink, error)
+}
+
+// APILister lists all private links visible to the API key
+type APILister struct {
+	logger       *zap.SugaredLogger
+	unmarshaller *jsonhttp.PaginatedJSONHTTPUnmarshaller[*apiresp.ListPrivateLinksResp, apiresp.ListPrivateLinksRespDataItem]
+}
+
+func NewAPILister(logger *zap.SugaredLogger,
+	APIKey, APISecret, APIURL string,
+	timeout time.Duration) (*APILister, error) {
+	logger = getComponentLogger(logger, "api-lister")
+
+	url, err := url.Parse(APIURL + "/v1/private-links")
+	if err != nil {
+		logger.Errorw("parsing API URL", "url", APIURL, "error", err)
+		return nil, fmt.Errorf("parsing API URL %q: %w", APIURL, err)
+	}
+
+	apiToken := base64.StdEncoding.EncodeToString([]byte(APIKey + ":" + APISecret))
+	httpClient := &http.Client{
+		Timeout: timeout,
+	}
+
+	unmarshaller := jsonhttp.NewPaginatedJSONHTTPUnmarshaller[*apiresp.ListPrivateLinksResp, apiresp.ListPrivateLinksRespDataItem](logger,
+		url,
+		apiToken,
+		httpClient)
+
+	return &APILister{
+		logger:       logger,
+		unmarshaller: unmarshaller,
+	}, nil
+}
+
+func (l *APILister) List() ([]*PrivateLink, error) {
+	items, err := l.unmarshaller.UnmarshallAllPagesFromHTTPGet()
+	if err != nil {
+		l.logger.Errorw("getting JSON HTTP responses", "error", err)
+		return nil, fmt.Errorf("getting JSON HTTP responses: %w", err)
+	}
+
+	privateLinks := make([]*PrivateLink, 0, len(items))
+	for _, item := range items {
+		state, err := l.convertState(item.State)
+		if err != nil {
+			l.logger.Errorw("converting State",
+				"id", item.ID,
+				"name", item.Name,
+				"state", item.State,
+				"error", err)
+			return nil, fmt.Errorf("converting State: %w", err)
+		}
+
+		privateLink := &PrivateLink{
+			ID:            item.ID,
+			Name:          item.Name,
+			GroupID:       item.GroupID,
+			Service:       item.Service,
+			Region:        item.Region,
+			CloudProvider: item.CloudProvider,
+			State:         state,
+		}
+
+		if state == StateFailed {
+			l.logger.Warnw("private link failed",
+				"id", privateLink.ID,
+				"name", privateLink.Name,
+				"state_summary", item.StateSummary)
+		}
+
+		l.logger.Infow("discovered private link",
+			"id", privateLink.ID,
+			"name", privateLink.Name,
+			"group_id", privateLink.GroupID)
+		privateLinks = append(privateLinks, privateLink)
+	}
+
+	l.logger.Infow("listed private links from API", "count", len(privateLinks))
+	return privateLinks, nil
+}
+
+func (l *APILister) convertState(apiState apiresp.State) (State, error) {
+	switch apiState {
+	case apiresp.StatePending:
+		return StatePending, nil
+	case apiresp.StateCreating:
+		return StateCreating, nil
+	case apiresp.StateReady:
+		return StateReady, nil
+	case apiresp.StateFailed:
+		return StateFailed, nil
+	case apiresp.StateDeleting:
+		return StateDeleting, nil
+	default:
+		l.logger.Errorw("illegal API State", "state", apiState)
+		return State(""), fmt.Errorf("illegal API State: %q", apiState)
+	}
+}
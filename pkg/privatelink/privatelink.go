@@ -0,0 +1,21 @@
+package privatelink
+
+type State string
+
+const (
+	StatePending  State = "pending"
+	StateCreating State = "creating"
+	StateReady    State = "ready"
+	StateFailed   State = "failed"
+	StateDeleting State = "deleting"
+)
+
+type PrivateLink struct {
+	ID            string
+	Name          string
+	GroupID       string
+	Service       string
+	Region        string
+	CloudProvider string
+	State         State
+}
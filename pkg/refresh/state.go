@@ -0,0 +1,94 @@
+package refresh
+
+import (
+	"sync"
+	"time"
+)
+
+// State is the outcome of the refreshes of the resources of a group
+type State struct {
+	GroupName     string
+	LastSuccess   time.Time // Zero if never refreshed successfully
+	LastError     error     // Nil if the last refresh was successful
+	LastErrorTime time.Time // Zero if never failed to refresh
+}
+
+// Reporter reports the refresh state of each group it refreshes
+type Reporter interface {
+	RefreshStates() []*State
+}
+
+// Tracker records the outcome of the refreshes of each group. It is safe for
+// concurrent use.
+type Tracker struct {
+	lock   *sync.RWMutex
+	states map[string]*State
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{
+		lock:   new(sync.RWMutex),
+		states: make(map[string]*State),
+	}
+}
+
+func (t *Tracker) Succeeded(groupName string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	state := t.getOrAdd(groupName)
+	state.LastSuccess = time.Now()
+	state.LastError = nil
+}
+
+func (t *Tracker) Failed(groupName string, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	state := t.getOrAdd(groupName)
+	state.LastError = err
+	state.LastErrorTime = time.Now()
+}
+
+// State returns a copy of the state of the group, which is zero-valued other than
+// the group name if the group has never been refreshed
+func (t *Tracker) State(groupName string) *State {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	state, ok := t.states[groupName]
+	if !ok {
+		return &State{GroupName: groupName}
+	}
+
+	stateCopy := *state
+	return &stateCopy
+}
+
+// Retain removes the states of groups other than those given, e.g. when the
+// refreshed groups change on reload
+func (t *Tracker) Retain(groupNames []string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	retained := make(map[string]struct{}, len(groupNames))
+	for _, groupName := range groupNames {
+		retained[groupName] = struct{}{}
+	}
+
+	for groupName := range t.states {
+		if _, ok := retained[groupName]; !ok {
+			delete(t.states, groupName)
+		}
+	}
+}
+
+func (t *Tracker) getOrAdd(groupName string) *State {
+	state, ok := t.states[groupName]
+	if !ok {
+		state = &State{GroupName: groupName}
+		t.states[groupName] = state
+	}
+
+	return state
+}
@@ -0,0 +1,83 @@
+package reload
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// FileWatcher reloads the config when the contents of any of the files change, e.g.
+// when a mounted Kubernetes secret is rotated. The files are polled rather than
+// watched with inotify, as mounted secrets are replaced by swapping symlinks.
+type FileWatcher struct {
+	Filenames    []string
+	PollInterval time.Duration
+	Reloader     *Reloader
+
+	digests map[string][sha256.Size]byte // Keyed by filename
+	logger  *zap.SugaredLogger
+}
+
+func NewFileWatcher(logger *zap.SugaredLogger,
+	filenames []string,
+	pollInterval time.Duration,
+	reloader *Reloader) *FileWatcher {
+	logger = getComponentLogger(logger, "file-watcher")
+
+	return &FileWatcher{
+		Filenames:    filenames,
+		PollInterval: pollInterval,
+		Reloader:     reloader,
+		digests:      make(map[string][sha256.Size]byte, len(filenames)),
+		logger:       logger,
+	}
+}
+
+// Run polls the files every poll interval until the context is cancelled. The contents
+// of the files when Run is called are taken as those of the running config.
+func (w *FileWatcher) Run(ctx context.Context) {
+	w.changed()
+
+	ticker := time.NewTicker(w.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Infow("stopping file watching")
+			return
+		case <-ticker.C:
+			if w.changed() {
+				// The error is already logged and recorded, and there is no caller to return it to
+				_ = w.Reloader.DoReload()
+			}
+		}
+	}
+}
+
+// changed records the digests of the contents of the files, returning whether any
+// differ from those previously recorded. Files which cannot be read, e.g. while being
+// replaced, are skipped until they can be read again.
+func (w *FileWatcher) changed() bool {
+	changed := false
+	for _, filename := range w.Filenames {
+		contents, err := os.ReadFile(filename)
+		if err != nil {
+			w.logger.Errorw("reading watched file", "filename", filename, "error", err)
+			continue
+		}
+
+		digest := sha256.Sum256(contents)
+		previous, ok := w.digests[filename]
+		if ok && digest != previous {
+			w.logger.Infow("watched file changed", "filename", filename)
+			changed = true
+		}
+		w.digests[filename] = digest
+	}
+
+	return changed
+}
@@ -0,0 +1,49 @@
+package reload
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestFileWatcherReloadsOnChange(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "api_secret")
+	if err := os.WriteFile(filename, []byte("s3cr3t\n"), 0o600); err != nil {
+		t.Fatalf("writing watched file: %v", err)
+	}
+
+	reloads := make(chan struct{}, 1)
+	reloader := NewReloader(zap.NewNop().Sugar(), func() error {
+		select {
+		case reloads <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+
+	watcher := NewFileWatcher(zap.NewNop().Sugar(), []string{filename}, 10*time.Millisecond, reloader)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watcher.Run(ctx)
+
+	select {
+	case <-reloads:
+		t.Fatal("got reload before the file changed, want none")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := os.WriteFile(filename, []byte("r0tated\n"), 0o600); err != nil {
+		t.Fatalf("rewriting watched file: %v", err)
+	}
+
+	select {
+	case <-reloads:
+	case <-time.After(5 * time.Second):
+		t.Fatal("got no reload after the file changed, want one")
+	}
+}
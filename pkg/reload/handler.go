@@ -0,0 +1,65 @@
+package reload
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const bearerPrefix = "Bearer "
+
+// Handler triggers a reload on an authenticated POST request. The request must carry
+// the reload token as a bearer token in the Authorization header.
+type Handler struct {
+	Reloader *Reloader
+
+	token  []byte
+	logger *zap.SugaredLogger
+}
+
+func NewHandler(logger *zap.SugaredLogger, reloader *Reloader, token string) *Handler {
+	logger = getComponentLogger(logger, "handler")
+
+	return &Handler{
+		Reloader: reloader,
+		token:    []byte(token),
+		logger:   logger,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authenticate(r) {
+		h.logger.Errorw("unauthenticated reload request", "remote_addr", r.RemoteAddr)
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	h.logger.Infow("received reload request", "remote_addr", r.RemoteAddr)
+	if err := h.Reloader.DoReload(); err != nil {
+		http.Error(w, "reloading config: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) authenticate(r *http.Request) bool {
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, bearerPrefix) {
+		return false
+	}
+
+	token := []byte(strings.TrimPrefix(authorization, bearerPrefix))
+
+	// Compare in constant time, so the token cannot be guessed from response timings
+	return subtle.ConstantTimeCompare(token, h.token) == 1
+}
@@ -0,0 +1,96 @@
+package reload
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Func reloads the config, returning an error if the new config could not be
+// applied. On error, the previous config must remain in effect.
+type Func func() error
+
+type Reporter interface {
+	// LastReload returns whether the last reload was successful, and the time of the
+	// last successful reload
+	LastReload() (successful bool, lastSuccess time.Time)
+}
+
+// Reloader runs reloads one at a time, on request or on SIGHUP, and records their
+// outcome. The initial load of the config counts as a successful reload.
+type Reloader struct {
+	Reload Func
+
+	reloadLock  *sync.Mutex
+	statusLock  *sync.RWMutex
+	successful  bool
+	lastSuccess time.Time
+	logger      *zap.SugaredLogger
+}
+
+func NewReloader(logger *zap.SugaredLogger, reload Func) *Reloader {
+	logger = getComponentLogger(logger, "reloader")
+
+	return &Reloader{
+		Reload:      reload,
+		reloadLock:  new(sync.Mutex),
+		statusLock:  new(sync.RWMutex),
+		successful:  true,
+		lastSuccess: time.Now(),
+		logger:      logger,
+	}
+}
+
+// Run reloads the config on each SIGHUP until the context is cancelled
+func (r *Reloader) Run(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Infow("stopping reloads on signal")
+			return
+		case <-signals:
+			r.logger.Infow("received SIGHUP")
+			// The error is already logged and recorded, and there is no caller to return it to
+			_ = r.DoReload()
+		}
+	}
+}
+
+// DoReload reloads the config, waiting for any reload already in progress to finish
+func (r *Reloader) DoReload() error {
+	r.reloadLock.Lock()
+	defer r.reloadLock.Unlock()
+
+	r.logger.Infow("reloading config")
+	err := r.Reload()
+
+	r.statusLock.Lock()
+	defer r.statusLock.Unlock()
+
+	if err != nil {
+		r.successful = false
+		r.logger.Errorw("reloading config", "error", err)
+		return err
+	}
+
+	r.successful = true
+	r.lastSuccess = time.Now()
+	r.logger.Infow("reloaded config")
+	return nil
+}
+
+func (r *Reloader) LastReload() (bool, time.Time) {
+	r.statusLock.RLock()
+	defer r.statusLock.RUnlock()
+
+	return r.successful, r.lastSuccess
+}
@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type cachedConfig struct {
+	config    *Config
+	described time.Time
+}
+
+type cachedColumns struct {
+	columns   []*Column
+	described time.Time
+}
+
+type tableKey struct {
+	connectorID string
+	schemaName  string
+	tableName   string
+}
+
+// CachingDescriber serves schema and column configs from a cache, so that every scrape
+// and compliance report does not trigger the underlying API calls. Cached configs are
+// described again once they are older than the TTL. Failures are not cached.
+type CachingDescriber struct {
+	Describer Describer
+	TTL       time.Duration
+
+	lock    *sync.Mutex
+	configs map[string]*cachedConfig // Keyed by connector ID
+	columns map[tableKey]*cachedColumns
+	logger  *zap.SugaredLogger
+}
+
+func NewCachingDescriber(logger *zap.SugaredLogger, describer Describer, ttl time.Duration) *CachingDescriber {
+	logger = getComponentLogger(logger, "caching-describer")
+
+	return &CachingDescriber{
+		Describer: describer,
+		TTL:       ttl,
+		lock:      new(sync.Mutex),
+		configs:   make(map[string]*cachedConfig),
+		columns:   make(map[tableKey]*cachedColumns),
+		logger:    logger,
+	}
+}
+
+func (d *CachingDescriber) Describe(connectorID string) (*Config, error) {
+	d.lock.Lock()
+	cached, ok := d.configs[connectorID]
+	d.lock.Unlock()
+	if ok && time.Since(cached.described) < d.TTL {
+		return cached.config, nil
+	}
+
+	// The lock is not held while describing, so that connectors can be described
+	// concurrently
+	config, err := d.Describer.Describe(connectorID)
+	if err != nil {
+		return nil, err
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.evictExpired()
+	d.configs[connectorID] = &cachedConfig{config, time.Now()}
+
+	return config, nil
+}
+
+func (d *CachingDescriber) DescribeColumns(connectorID, schemaName, tableName string) ([]*Column, error) {
+	key := tableKey{connectorID, schemaName, tableName}
+
+	d.lock.Lock()
+	cached, ok := d.columns[key]
+	d.lock.Unlock()
+	if ok && time.Since(cached.described) < d.TTL {
+		return cached.columns, nil
+	}
+
+	columns, err := d.Describer.DescribeColumns(connectorID, schemaName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.evictExpired()
+	d.columns[key] = &cachedColumns{columns, time.Now()}
+
+	return columns, nil
+}
+
+// evictExpired removes expired entries, so that the entries of deleted connectors and
+// tables do not accumulate. The lock must be held.
+func (d *CachingDescriber) evictExpired() {
+	for connectorID, cached := range d.configs {
+		if time.Since(cached.described) >= d.TTL {
+			delete(d.configs, connectorID)
+		}
+	}
+
+	for key, cached := range d.columns {
+		if time.Since(cached.described) >= d.TTL {
+			delete(d.columns, key)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package schema
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	jsonhttp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/jsonhttp"
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp/schema"
+	"go.uber.org/zap"
+)
+
+type Describer interface {
+	Describe(connectorID string) (*Config, error)
+	// DescribeColumns describes all columns of the table, unlike Describe, which only
+	// describes the columns which have been explicitly configured
+	DescribeColumns(connectorID, schemaName, tableName string) ([]*Column, error)
+}
+
+type APIDescriber struct {
+	APIURL string
+
+	apiToken   string
+	httpClient *http.Client
+	logger     *zap.SugaredLogger
+}
+
+func NewAPIDescriber(logger *zap.SugaredLogger,
+	APIKey, APISecret, APIURL string,
+	timeout time.Duration) (*APIDescriber, error) {
+	logger = getComponentLogger(logger, "api-describer")
+
+	if _, err := url.Parse(APIURL); err != nil {
+		logger.Errorw("parsing API URL", "url", APIURL, "error", err)
+		return nil, fmt.Errorf("parsing API URL %q: %w", APIURL, err)
+	}
+
+	apiToken := base64.StdEncoding.EncodeToString([]byte(APIKey + ":" + APISecret))
+	httpClient := &http.Client{
+		Timeout: timeout,
+	}
+
+	return &APIDescriber{
+		APIURL:     APIURL,
+		apiToken:   apiToken,
+		httpClient: httpClient,
+		logger:     logger,
+	}, nil
+}
+
+func (d *APIDescriber) Describe(connectorID string) (*Config, error) {
+	// The URL differs per connector, so an unmarshaller is constructed for each call
+	url, err := url.Parse(fmt.Sprintf("%s/v1/connectors/%s/schemas", d.APIURL, url.PathEscape(connectorID)))
+	if err != nil {
+		d.logger.Errorw("parsing API URL", "url", d.APIURL, "connector_id", connectorID, "error", err)
+		return nil, fmt.Errorf("parsing API URL %q: %w", d.APIURL, err)
+	}
+
+	unmarshaller := jsonhttp.NewJSONHTTPUnmarshaller[*apiresp.SchemaConfigResp](d.logger,
+		url,
+		d.apiToken,
+		d.httpClient)
+
+	schemaConfigResp, err := unmarshaller.UnmarshallJSONFromHTTPGet()
+	if err != nil {
+		d.logger.Errorw("getting JSON HTTP response", "connector_id", connectorID, "error", err)
+		return nil, fmt.Errorf("getting JSON HTTP response: %w", err)
+	}
+
+	config := &Config{
+		ConnectorID: connectorID,
+		Schemas:     make([]*Schema, 0, len(schemaConfigResp.Data.Schemas)),
+	}
+	for _, schemaName := range sortedKeys(schemaConfigResp.Data.Schemas) {
+		apiSchema := schemaConfigResp.Data.Schemas[schemaName]
+		schema := &Schema{
+			Name:    schemaName,
+			Enabled: apiSchema.Enabled,
+			Tables:  make([]*Table, 0, len(apiSchema.Tables)),
+		}
+
+		for _, tableName := range sortedKeys(apiSchema.Tables) {
+			apiTable := apiSchema.Tables[tableName]
+			table := &Table{
+				Name:    tableName,
+				Enabled: apiTable.Enabled,
+				Columns: make([]*Column, 0, len(apiTable.Columns)),
+			}
+
+			for _, columnName := range sortedKeys(apiTable.Columns) {
+				apiColumn := apiTable.Columns[columnName]
+				table.Columns = append(table.Columns, &Column{
+					Name:    columnName,
+					Enabled: apiColumn.Enabled,
+					Hashed:  apiColumn.Hashed,
+				})
+			}
+
+			schema.Tables = append(schema.Tables, table)
+		}
+
+		config.Schemas = append(config.Schemas, schema)
+	}
+
+	d.logger.Infow("described schema config from API",
+		"connector_id", connectorID,
+		"schema_count", len(config.Schemas))
+	return config, nil
+}
+
+func (d *APIDescriber) DescribeColumns(connectorID, schemaName, tableName string) ([]*Column, error) {
+	// The URL differs per table, so an unmarshaller is constructed for each call
+	url, err := url.Parse(fmt.Sprintf("%s/v1/connectors/%s/schemas/%s/tables/%s/columns",
+		d.APIURL,
+		url.PathEscape(connectorID),
+		url.PathEscape(schemaName),
+		url.PathEscape(tableName)))
+	if err != nil {
+		d.logger.Errorw("parsing API URL",
+			"url", d.APIURL,
+			"connector_id", connectorID,
+			"schema", schemaName,
+			"table", tableName,
+			"error", err)
+		return nil, fmt.Errorf("parsing API URL %q: %w", d.APIURL, err)
+	}
+
+	unmarshaller := jsonhttp.NewJSONHTTPUnmarshaller[*apiresp.ColumnConfigResp](d.logger,
+		url,
+		d.apiToken,
+		d.httpClient)
+
+	columnConfigResp, err := unmarshaller.UnmarshallJSONFromHTTPGet()
+	if err != nil {
+		d.logger.Errorw("getting JSON HTTP response",
+			"connector_id", connectorID,
+			"schema", schemaName,
+			"table", tableName,
+			"error", err)
+		return nil, fmt.Errorf("getting JSON HTTP response: %w", err)
+	}
+
+	columns := make([]*Column, 0, len(columnConfigResp.Data.Columns))
+	for _, columnName := range sortedKeys(columnConfigResp.Data.Columns) {
+		apiColumn := columnConfigResp.Data.Columns[columnName]
+		columns = append(columns, &Column{
+			Name:    columnName,
+			Enabled: apiColumn.Enabled,
+			Hashed:  apiColumn.Hashed,
+		})
+	}
+
+	d.logger.Infow("described column config from API",
+		"connector_id", connectorID,
+		"schema", schemaName,
+		"table", tableName,
+		"column_count", len(columns))
+	return columns, nil
+}
+
+// sortedKeys returns the keys of the map in order, so that the schema config
+// is presented deterministically regardless of map iteration order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
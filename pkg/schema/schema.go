@@ -0,0 +1,26 @@
+package schema
+
+// Config is the schema configuration of a single connector, describing which schemas,
+// tables and columns are synced to the destination.
+type Config struct {
+	ConnectorID string
+	Schemas     []*Schema
+}
+
+type Schema struct {
+	Name    string
+	Enabled bool
+	Tables  []*Table
+}
+
+type Table struct {
+	Name    string
+	Enabled bool
+	Columns []*Column
+}
+
+type Column struct {
+	Name    string
+	Enabled bool
+	Hashed  bool
+}
@@ -0,0 +1,12 @@
+package secret
+
+import "errors"
+
+// ErrNotFound is returned (wrapped) by a Provider when it does not hold the secret
+var ErrNotFound = errors.New("secret not found")
+
+// Provider gets secrets from a secret store. Secrets are fetched on every call, so
+// that rotated secrets are picked up.
+type Provider interface {
+	Get(key string) (string, error)
+}
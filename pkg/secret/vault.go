@@ -0,0 +1,107 @@
+package secret
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type vaultKVv2Resp struct {
+	Data struct {
+		Data map[string]any
+	}
+}
+
+// VaultKVProvider gets secrets from the keys of a single secret in a HashiCorp Vault
+// KV version 2 secrets engine
+type VaultKVProvider struct {
+	Address string
+	Mount   string
+	Path    string
+
+	token      string
+	httpClient *http.Client
+	logger     *zap.SugaredLogger
+}
+
+func NewVaultKVProvider(logger *zap.SugaredLogger,
+	address, token, mount, path string,
+	timeout time.Duration) (*VaultKVProvider, error) {
+	logger = getComponentLogger(logger, "vault-kv-provider")
+
+	if _, err := url.Parse(address); err != nil {
+		logger.Errorw("parsing Vault address", "address", address, "error", err)
+		return nil, fmt.Errorf("parsing Vault address %q: %w", address, err)
+	}
+
+	return &VaultKVProvider{
+		Address: strings.TrimSuffix(address, "/"),
+		Mount:   strings.Trim(mount, "/"),
+		Path:    strings.Trim(path, "/"),
+		token:   token,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+		logger: logger,
+	}, nil
+}
+
+func (p *VaultKVProvider) Get(key string) (string, error) {
+	url := fmt.Sprintf("%s/v1/%s/data/%s", p.Address, p.Mount, p.Path)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		p.logger.Errorw("constructing HTTP request", "url", url, "error", err)
+		return "", fmt.Errorf("constructing HTTP request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		p.logger.Errorw("performing HTTP request", "url", url, "error", err)
+		return "", fmt.Errorf("performing HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		p.logger.Errorw("Vault secret not found", "mount", p.Mount, "path", p.Path)
+		return "", fmt.Errorf("Vault secret %q in mount %q: %w", p.Path, p.Mount, ErrNotFound)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		p.logger.Errorw("unexpected HTTP response status code", "url", url, "status_code", resp.StatusCode)
+		return "", fmt.Errorf("unexpected HTTP response status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		p.logger.Errorw("reading HTTP response body", "url", url, "error", err)
+		return "", fmt.Errorf("reading HTTP response body: %w", err)
+	}
+
+	vaultResp := new(vaultKVv2Resp)
+	if err := json.Unmarshal(body, vaultResp); err != nil {
+		p.logger.Errorw("unmarshalling Vault response", "url", url, "error", err)
+		return "", fmt.Errorf("unmarshalling Vault response: %w", err)
+	}
+
+	value, ok := vaultResp.Data.Data[key]
+	if !ok {
+		p.logger.Infow("key not found in Vault secret", "mount", p.Mount, "path", p.Path, "key", key)
+		return "", fmt.Errorf("key %q in Vault secret %q: %w", key, p.Path, ErrNotFound)
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		p.logger.Errorw("Vault secret value not a string", "mount", p.Mount, "path", p.Path, "key", key)
+		return "", fmt.Errorf("value of key %q in Vault secret %q not a string", key, p.Path)
+	}
+
+	p.logger.Infow("got secret from Vault", "mount", p.Mount, "path", p.Path, "key", key)
+	return str, nil
+}
@@ -0,0 +1,113 @@
+package secret
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	testVaultToken = "test-token"
+	testVaultMount = "kv"
+	testVaultPath  = "fivetran/exporter"
+)
+
+// newTestVaultServer stands in for a Vault server with a KV version 2 secrets engine,
+// holding a single secret with only the API secret set, under the key read by the
+// secret config sourcer
+func newTestVaultServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != testVaultToken {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/"+testVaultMount+"/data/"+testVaultPath {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"data": {
+				"data": {"api_secret": "s3cr3t"},
+				"metadata": {"version": 1}
+			}
+		}`))
+	}))
+}
+
+func newTestVaultKVProvider(t *testing.T, address, token, path string) *VaultKVProvider {
+	t.Helper()
+
+	provider, err := NewVaultKVProvider(zap.NewNop().Sugar(), address, token, testVaultMount, path, time.Second)
+	if err != nil {
+		t.Fatalf("constructing Vault KV provider: %v", err)
+	}
+
+	return provider
+}
+
+func TestVaultKVProviderGet(t *testing.T) {
+	server := newTestVaultServer(t)
+	defer server.Close()
+
+	provider := newTestVaultKVProvider(t, server.URL, testVaultToken, testVaultPath)
+
+	value, err := provider.Get("api_secret")
+	if err != nil {
+		t.Fatalf("getting secret: %v", err)
+	}
+
+	if value != "s3cr3t" {
+		t.Errorf("got secret %q, want %q", value, "s3cr3t")
+	}
+}
+
+func TestVaultKVProviderGetMissingKey(t *testing.T) {
+	server := newTestVaultServer(t)
+	defer server.Close()
+
+	provider := newTestVaultKVProvider(t, server.URL, testVaultToken, testVaultPath)
+
+	if _, err := provider.Get("api_key"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestVaultKVProviderGetMissingSecret(t *testing.T) {
+	server := newTestVaultServer(t)
+	defer server.Close()
+
+	provider := newTestVaultKVProvider(t, server.URL, testVaultToken, "fivetran/missing")
+
+	if _, err := provider.Get("api_secret"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestVaultKVProviderGetForbidden(t *testing.T) {
+	server := newTestVaultServer(t)
+	defer server.Close()
+
+	provider := newTestVaultKVProvider(t, server.URL, "wrong-token", testVaultPath)
+
+	_, err := provider.Get("api_secret")
+	if err == nil {
+		t.Fatal("got no error, want error")
+	}
+
+	// A rejected token must not be mistaken for a secret which is not set, else the
+	// setting would silently fall through to the lower-precedence config layers
+	if errors.Is(err, ErrNotFound) {
+		t.Errorf("got error %v, want error other than %v", err, ErrNotFound)
+	}
+}
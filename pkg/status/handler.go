@@ -0,0 +1,209 @@
+package status
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/connector"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/group"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/refresh"
+	"go.uber.org/zap"
+)
+
+var (
+	setupStates = []connector.SetupState{
+		connector.SetupStateConnected,
+		connector.SetupStateIncomplete,
+		connector.SetupStateBroken,
+	}
+	syncStates = []connector.SyncState{
+		connector.SyncStateScheduled,
+		connector.SyncStateSyncing,
+		connector.SyncStateRescheduled,
+		connector.SyncStatePaused,
+	}
+)
+
+// ConnectorCollector is the collector of the connectors of an account, which records
+// the connectors and the outcome of each listing of the connectors of a group
+type ConnectorCollector interface {
+	refresh.Reporter
+	Connectors() []*connector.Connector
+}
+
+// Source is the collected groups and connector collector of a single account
+type Source struct {
+	Account            string
+	Groups             []*group.Group
+	ConnectorCollector ConnectorCollector
+}
+
+// Setting is a setting of the effective config, with any secret value redacted
+type Setting struct {
+	Name  string
+	Value string
+}
+
+type page struct {
+	MetricsPath string
+	Time        time.Time
+	Accounts    []*pageAccount
+	Config      []*Setting
+}
+
+type pageAccount struct {
+	Name   string
+	Groups []*pageGroup
+}
+
+type pageGroup struct {
+	*refresh.State
+	ID               string
+	ConnectorCount   int
+	PausedCount      int
+	SetupStateCounts []*pageStateCount
+	SyncStateCounts  []*pageStateCount
+}
+
+type pageStateCount struct {
+	State string
+	Count int
+}
+
+// Handler serves an HTML page of the status of the collected groups of all accounts,
+// and the effective config. The status is that recorded by the collectors, so
+// serving the page does not make any calls to the API.
+type Handler struct {
+	Sources     []*Source
+	Config      []*Setting
+	MetricsPath string
+
+	lock   *sync.RWMutex
+	logger *zap.SugaredLogger
+}
+
+func NewHandler(logger *zap.SugaredLogger, sources []*Source, config []*Setting, metricsPath string) *Handler {
+	logger = getComponentLogger(logger, "handler")
+
+	return &Handler{
+		Sources:     sources,
+		Config:      config,
+		MetricsPath: metricsPath,
+		lock:        new(sync.RWMutex),
+		logger:      logger,
+	}
+}
+
+// SetSources atomically replaces the sources and config, e.g. when the config is
+// reloaded
+func (h *Handler) SetSources(sources []*Source, config []*Setting) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.Sources = sources
+	h.Config = config
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The page is served on the root path, which matches all paths not otherwise handled
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodHead)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.lock.RLock()
+	sources := h.Sources
+	config := h.Config
+	h.lock.RUnlock()
+
+	p := &page{
+		MetricsPath: h.MetricsPath,
+		Time:        time.Now(),
+		Accounts:    make([]*pageAccount, 0, len(sources)),
+		Config:      config,
+	}
+	for _, source := range sources {
+		p.Accounts = append(p.Accounts, newPageAccount(source))
+	}
+
+	// The page is rendered to a buffer, so that a failure to render is not served as
+	// a partial page
+	body := new(bytes.Buffer)
+	if err := pageTemplate.Execute(body, p); err != nil {
+		h.logger.Errorw("rendering status page", "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := body.WriteTo(w); err != nil {
+		h.logger.Errorw("writing status page", "error", err)
+	}
+}
+
+func newPageAccount(source *Source) *pageAccount {
+	states := make(map[string]*refresh.State)
+	for _, state := range source.ConnectorCollector.RefreshStates() {
+		states[state.GroupName] = state
+	}
+
+	connectorsByGroup := make(map[string][]*connector.Connector)
+	for _, conn := range source.ConnectorCollector.Connectors() {
+		connectorsByGroup[conn.GroupName] = append(connectorsByGroup[conn.GroupName], conn)
+	}
+
+	account := &pageAccount{
+		Name:   source.Account,
+		Groups: make([]*pageGroup, 0, len(source.Groups)),
+	}
+	for _, g := range source.Groups {
+		state, ok := states[g.Name]
+		if !ok {
+			state = &refresh.State{GroupName: g.Name}
+		}
+
+		account.Groups = append(account.Groups, newPageGroup(g, state, connectorsByGroup[g.Name]))
+	}
+
+	return account
+}
+
+func newPageGroup(g *group.Group, state *refresh.State, connectors []*connector.Connector) *pageGroup {
+	setupStateCounts := make(map[connector.SetupState]int)
+	syncStateCounts := make(map[connector.SyncState]int)
+	pausedCount := 0
+	for _, conn := range connectors {
+		setupStateCounts[conn.SetupState]++
+		syncStateCounts[conn.SyncState]++
+		if conn.Paused {
+			pausedCount++
+		}
+	}
+
+	pg := &pageGroup{
+		State:            state,
+		ID:               g.ID,
+		ConnectorCount:   len(connectors),
+		PausedCount:      pausedCount,
+		SetupStateCounts: make([]*pageStateCount, 0, len(setupStates)),
+		SyncStateCounts:  make([]*pageStateCount, 0, len(syncStates)),
+	}
+	for _, setupState := range setupStates {
+		pg.SetupStateCounts = append(pg.SetupStateCounts,
+			&pageStateCount{string(setupState), setupStateCounts[setupState]})
+	}
+	for _, syncState := range syncStates {
+		pg.SyncStateCounts = append(pg.SyncStateCounts,
+			&pageStateCount{string(syncState), syncStateCounts[syncState]})
+	}
+
+	return pg
+}
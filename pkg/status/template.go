@@ -0,0 +1,79 @@
+package status
+
+import (
+	"html/template"
+	"time"
+)
+
+var pageTemplate = template.Must(template.New("status").Funcs(template.FuncMap{
+	"formatTime": formatTime,
+}).Parse(pageTemplateText))
+
+// formatTime formats the time as RFC 3339, or "never" if it is zero
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+const pageTemplateText = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Fivetran Exporter</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+th { background: #eee; }
+.error { color: #b00; }
+</style>
+</head>
+<body>
+<h1>Fivetran Exporter</h1>
+<p><a href="{{.MetricsPath}}">Metrics</a> &middot; Generated at {{formatTime .Time}}</p>
+{{range .Accounts}}
+<h2>Account {{.Name}}</h2>
+<table>
+<tr>
+<th>Group</th>
+<th>ID</th>
+<th>Last refresh</th>
+<th>Last error</th>
+<th>Connectors</th>
+<th>Paused</th>
+<th>Setup states</th>
+<th>Sync states</th>
+</tr>
+{{range .Groups}}
+<tr>
+<td>{{.GroupName}}</td>
+<td>{{.ID}}</td>
+<td>{{formatTime .LastSuccess}}</td>
+<td>{{if .LastError}}<span class="error">{{.LastError}}</span> at {{formatTime .LastErrorTime}}{{else}}none{{end}}</td>
+<td>{{.ConnectorCount}}</td>
+<td>{{.PausedCount}}</td>
+<td>{{range .SetupStateCounts}}{{.State}}: {{.Count}}<br>{{end}}</td>
+<td>{{range .SyncStateCounts}}{{.State}}: {{.Count}}<br>{{end}}</td>
+</tr>
+{{end}}
+</table>
+{{end}}
+<h2>Configuration</h2>
+<table>
+<tr>
+<th>Setting</th>
+<th>Value</th>
+</tr>
+{{range .Config}}
+<tr>
+<td>{{.Name}}</td>
+<td>{{.Value}}</td>
+</tr>
+{{end}}
+</table>
+</body>
+</html>
+`
@@ -0,0 +1,175 @@
+package transformation
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	jsonhttp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/jsonhttp"
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp/transformation"
+	"go.uber.org/zap"
+)
+
+type Lister interface {
+	List() ([]*Transformation, error)
+	GetGroupID() string
+	GetGroupName() string
+}
+
+// APILister lists the transformations of every dbt project in a group.
+// This requires one API call per project.
+type APILister struct {
+	GroupID   string
+	GroupName string
+	APIURL    string
+
+	apiToken     string
+	httpClient   *http.Client
+	logger       *zap.SugaredLogger
+	unmarshaller *jsonhttp.PaginatedJSONHTTPUnmarshaller[*apiresp.ListProjectsResp, apiresp.ListProjectsRespDataItem]
+}
+
+func NewAPILister(logger *zap.SugaredLogger,
+	APIKey, APISecret, APIURL, groupID, groupName string,
+	timeout time.Duration) (*APILister, error) {
+	logger = getComponentLogger(logger, "api-lister")
+
+	url, err := url.Parse(fmt.Sprintf("%s/v1/dbt/projects?group_id=%s", APIURL, url.QueryEscape(groupID)))
+	if err != nil {
+		logger.Errorw("parsing API URL", "url", APIURL, "error", err)
+		return nil, fmt.Errorf("parsing API URL %q: %w", APIURL, err)
+	}
+
+	apiToken := base64.StdEncoding.EncodeToString([]byte(APIKey + ":" + APISecret))
+	httpClient := &http.Client{
+		Timeout: timeout,
+	}
+
+	unmarshaller := jsonhttp.NewPaginatedJSONHTTPUnmarshaller[*apiresp.ListProjectsResp, apiresp.ListProjectsRespDataItem](logger,
+		url,
+		apiToken,
+		httpClient)
+
+	return &APILister{
+		GroupID:      groupID,
+		GroupName:    groupName,
+		APIURL:       APIURL,
+		apiToken:     apiToken,
+		httpClient:   httpClient,
+		logger:       logger,
+		unmarshaller: unmarshaller,
+	}, nil
+}
+
+func (l *APILister) List() ([]*Transformation, error) {
+	projects, err := l.unmarshaller.UnmarshallAllPagesFromHTTPGet()
+	if err != nil {
+		l.logger.Errorw("getting JSON HTTP responses", "group_name", l.GroupName, "error", err)
+		return nil, fmt.Errorf("getting JSON HTTP responses: %w", err)
+	}
+
+	transformations := make([]*Transformation, 0)
+	for _, project := range projects {
+		projectTransformations, err := l.listForProject(project.ID)
+		if err != nil {
+			l.logger.Errorw("listing project transformations",
+				"project_id", project.ID,
+				"group_name", l.GroupName,
+				"error", err)
+			return nil, fmt.Errorf("listing transformations of project %q: %w", project.ID, err)
+		}
+
+		transformations = append(transformations, projectTransformations...)
+	}
+
+	l.logger.Infow("listed transformations from API",
+		"group_id", l.GroupID,
+		"group_name", l.GroupName,
+		"count", len(transformations))
+	return transformations, nil
+}
+
+func (l *APILister) GetGroupID() string {
+	return l.GroupID
+}
+
+func (l *APILister) GetGroupName() string {
+	return l.GroupName
+}
+
+func (l *APILister) listForProject(projectID string) ([]*Transformation, error) {
+	// The URL differs per project, so an unmarshaller is constructed for each project
+	url, err := url.Parse(fmt.Sprintf("%s/v1/dbt/projects/%s/transformations", l.APIURL, url.PathEscape(projectID)))
+	if err != nil {
+		l.logger.Errorw("parsing API URL", "url", l.APIURL, "project_id", projectID, "error", err)
+		return nil, fmt.Errorf("parsing API URL %q: %w", l.APIURL, err)
+	}
+
+	unmarshaller := jsonhttp.NewPaginatedJSONHTTPUnmarshaller[*apiresp.ListTransformationsResp, apiresp.ListTransformationsRespDataItem](l.logger,
+		url,
+		l.apiToken,
+		l.httpClient)
+
+	items, err := unmarshaller.UnmarshallAllPagesFromHTTPGet()
+	if err != nil {
+		l.logger.Errorw("getting JSON HTTP responses", "project_id", projectID, "error", err)
+		return nil, fmt.Errorf("getting JSON HTTP responses: %w", err)
+	}
+
+	transformations := make([]*Transformation, 0, len(items))
+	for _, item := range items {
+		status, err := l.convertStatus(item.Status)
+		if err != nil {
+			l.logger.Errorw("converting Status",
+				"id", item.ID,
+				"name", item.OutputModelName,
+				"group_id", l.GroupID,
+				"group_name", l.GroupName,
+				"status", item.Status,
+				"error", err)
+			return nil, fmt.Errorf("converting Status: %w", err)
+		}
+
+		transformation := &Transformation{
+			ID:           item.ID,
+			Name:         item.OutputModelName,
+			ProjectID:    projectID,
+			GroupID:      l.GroupID,
+			GroupName:    l.GroupName,
+			Paused:       item.Paused,
+			Status:       status,
+			LastRun:      item.LastRun,
+			NextRun:      item.NextRun,
+			ScheduleType: strings.ToLower(item.Schedule.ScheduleType),
+			IntervalMins: item.Schedule.IntervalMins,
+		}
+
+		l.logger.Infow("discovered transformation",
+			"id", transformation.ID,
+			"name", transformation.Name,
+			"group_id", transformation.GroupID,
+			"group_name", transformation.GroupName)
+		transformations = append(transformations, transformation)
+	}
+
+	return transformations, nil
+}
+
+func (l *APILister) convertStatus(apiStatus apiresp.Status) (Status, error) {
+	switch apiStatus {
+	case apiresp.StatusSucceeded:
+		return StatusSucceeded, nil
+	case apiresp.StatusFailed:
+		return StatusFailed, nil
+	case apiresp.StatusRunning:
+		return StatusRunning, nil
+	case apiresp.StatusPending:
+		return StatusPending, nil
+	default:
+		l.logger.Errorw("illegal API Status", "status", apiStatus)
+		return Status(""), fmt.Errorf("illegal API Status: %q", apiStatus)
+	}
+}
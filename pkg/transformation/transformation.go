@@ -0,0 +1,27 @@
+package transformation
+
+import "time"
+
+// Transformation is a Fivetran-managed dbt transformation
+type Transformation struct {
+	ID           string
+	Name         string // The output model name
+	ProjectID    string
+	GroupID      string
+	GroupName    string
+	Paused       bool
+	Status       Status
+	LastRun      *time.Time // Nil if the transformation has never run
+	NextRun      *time.Time // Nil if the transformation is not scheduled
+	ScheduleType string
+	IntervalMins int // Only set for interval schedules
+}
+
+type Status string
+
+const (
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusRunning   Status = "running"
+	StatusPending   Status = "pending"
+)
@@ -0,0 +1,126 @@
+package usage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RefreshReporter is implemented by listers which refresh in the background, so that the
+// outcome of the refreshes can be reported at scrape time
+type RefreshReporter interface {
+	// LastRefresh returns the time of the last successful refresh, which is zero if never
+	// refreshed successfully, and the total number of failed refreshes
+	LastRefresh() (lastSuccess time.Time, failures uint64)
+}
+
+// CachingLister serves usage from a cache which is refreshed in the background on a
+// slow schedule, so that scrapes never trigger the expensive underlying API calls.
+// If a refresh fails, the previously cached usage continues to be served.
+type CachingLister struct {
+	Lister          Lister
+	RefreshInterval time.Duration
+
+	// The group of the lister cannot change, so it is read once on construction
+	groupID     string
+	groupName   string
+	lock        *sync.RWMutex
+	usages      []*Usage
+	lastRefresh time.Time
+	failures    uint64
+	logger      *zap.SugaredLogger
+}
+
+func NewCachingLister(logger *zap.SugaredLogger, lister Lister, refreshInterval time.Duration) *CachingLister {
+	logger = getComponentLogger(logger, "caching-lister")
+
+	return &CachingLister{
+		Lister:          lister,
+		RefreshInterval: refreshInterval,
+		groupID:         lister.GetGroupID(),
+		groupName:       lister.GetGroupName(),
+		lock:            new(sync.RWMutex),
+		logger:          logger,
+	}
+}
+
+// SetLister replaces the underlying lister of the same group, e.g. when the credentials
+// change on reload. The cached usage continues to be served until the next refresh.
+func (l *CachingLister) SetLister(lister Lister) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.Lister = lister
+}
+
+// Run refreshes the cache immediately, and then every refresh interval until the
+// context is cancelled
+func (l *CachingLister) Run(ctx context.Context) {
+	ticker := time.NewTicker(l.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		l.refresh()
+
+		select {
+		case <-ctx.Done():
+			l.logger.Infow("stopping cache refresh", "group_name", l.GetGroupName())
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// List returns the cached usage, which is empty until the cache is first populated.
+// Not yet being populated is not an error, as it is expected while the exporter starts.
+func (l *CachingLister) List() ([]*Usage, error) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	if l.lastRefresh.IsZero() {
+		l.logger.Infow("usage cache not yet populated", "group_name", l.GetGroupName())
+		return []*Usage{}, nil
+	}
+
+	return l.usages, nil
+}
+
+func (l *CachingLister) LastRefresh() (time.Time, uint64) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return l.lastRefresh, l.failures
+}
+
+func (l *CachingLister) GetGroupID() string {
+	return l.groupID
+}
+
+func (l *CachingLister) GetGroupName() string {
+	return l.groupName
+}
+
+func (l *CachingLister) refresh() {
+	l.lock.RLock()
+	lister := l.Lister
+	l.lock.RUnlock()
+
+	usages, err := lister.List()
+	if err != nil {
+		l.lock.Lock()
+		l.failures++
+		l.lock.Unlock()
+
+		l.logger.Errorw("refreshing usage cache", "group_name", l.GetGroupName(), "error", err)
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.usages = usages
+	l.lastRefresh = time.Now()
+
+	l.logger.Infow("refreshed usage cache", "group_name", l.GetGroupName(), "count", len(usages))
+}
@@ -0,0 +1,134 @@
+package usage
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	jsonhttp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/jsonhttp"
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp/usage"
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/connector"
+	"go.uber.org/zap"
+)
+
+type Lister interface {
+	List() ([]*Usage, error)
+	GetGroupID() string
+	GetGroupName() string
+}
+
+// APILister lists the usage of every connector in a group. This requires one API call
+// per connector, so it should not be called on every scrape (see CachingLister).
+type APILister struct {
+	APIURL          string
+	ConnectorLister connector.Lister
+
+	apiToken   string
+	httpClient *http.Client
+	logger     *zap.SugaredLogger
+}
+
+func NewAPILister(logger *zap.SugaredLogger,
+	APIKey, APISecret, APIURL string,
+	connectorLister connector.Lister,
+	timeout time.Duration) (*APILister, error) {
+	logger = getComponentLogger(logger, "api-lister")
+
+	if _, err := url.Parse(APIURL); err != nil {
+		logger.Errorw("parsing API URL", "url", APIURL, "error", err)
+		return nil, fmt.Errorf("parsing API URL %q: %w", APIURL, err)
+	}
+
+	apiToken := base64.StdEncoding.EncodeToString([]byte(APIKey + ":" + APISecret))
+	httpClient := &http.Client{
+		Timeout: timeout,
+	}
+
+	return &APILister{
+		APIURL:          APIURL,
+		ConnectorLister: connectorLister,
+		apiToken:        apiToken,
+		httpClient:      httpClient,
+		logger:          logger,
+	}, nil
+}
+
+func (l *APILister) List() ([]*Usage, error) {
+	connectors, err := l.ConnectorLister.List()
+	if err != nil {
+		l.logger.Errorw("listing connectors", "group_name", l.GetGroupName(), "error", err)
+		return nil, fmt.Errorf("listing connectors: %w", err)
+	}
+
+	usages := make([]*Usage, 0, len(connectors))
+	for _, conn := range connectors {
+		connectorUsages, err := l.listForConnector(conn)
+		if err != nil {
+			l.logger.Errorw("listing connector usage",
+				"id", conn.ID,
+				"name", conn.Name,
+				"group_name", conn.GroupName,
+				"error", err)
+			return nil, fmt.Errorf("listing usage for connector %q: %w", conn.Name, err)
+		}
+
+		usages = append(usages, connectorUsages...)
+	}
+
+	l.logger.Infow("listed connector usage from API",
+		"group_id", l.GetGroupID(),
+		"group_name", l.GetGroupName(),
+		"count", len(usages))
+	return usages, nil
+}
+
+func (l *APILister) GetGroupID() string {
+	return l.ConnectorLister.GetGroupID()
+}
+
+func (l *APILister) GetGroupName() string {
+	return l.ConnectorLister.GetGroupName()
+}
+
+func (l *APILister) listForConnector(conn *connector.Connector) ([]*Usage, error) {
+	// The URL differs per connector, so an unmarshaller is constructed for each call
+	url, err := url.Parse(fmt.Sprintf("%s/v1/connectors/%s/usage", l.APIURL, url.PathEscape(conn.ID)))
+	if err != nil {
+		l.logger.Errorw("parsing API URL", "url", l.APIURL, "connector_id", conn.ID, "error", err)
+		return nil, fmt.Errorf("parsing API URL %q: %w", l.APIURL, err)
+	}
+
+	unmarshaller := jsonhttp.NewJSONHTTPUnmarshaller[*apiresp.ConnectorUsageResp](l.logger,
+		url,
+		l.apiToken,
+		l.httpClient)
+
+	connectorUsageResp, err := unmarshaller.UnmarshallJSONFromHTTPGet()
+	if err != nil {
+		l.logger.Errorw("getting JSON HTTP response", "connector_id", conn.ID, "error", err)
+		return nil, fmt.Errorf("getting JSON HTTP response: %w", err)
+	}
+
+	usages := make([]*Usage, 0, len(connectorUsageResp.Data.Items))
+	for _, item := range connectorUsageResp.Data.Items {
+		usage := &Usage{
+			ConnectorID:       conn.ID,
+			ConnectorName:     conn.Name,
+			GroupID:           conn.GroupID,
+			GroupName:         conn.GroupName,
+			Month:             item.Month,
+			MonthlyActiveRows: item.MonthlyActiveRows,
+		}
+
+		l.logger.Infow("discovered connector usage",
+			"id", conn.ID,
+			"name", conn.Name,
+			"group_name", conn.GroupName,
+			"month", usage.Month)
+		usages = append(usages, usage)
+	}
+
+	return usages, nil
+}
@@ -0,0 +1,11 @@
+package usage
+
+// Usage is the number of monthly active rows (MAR) of a connector for a single month
+type Usage struct {
+	ConnectorID       string
+	ConnectorName     string
+	GroupID           string
+	GroupName         string
+	Month             string
+	MonthlyActiveRows int64
+}
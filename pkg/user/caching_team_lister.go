@@ -0,0 +1,115 @@
+package user
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RefreshReporter is implemented by listers which refresh in the background, so that the
+// outcome of the refreshes can be reported at scrape time
+type RefreshReporter interface {
+	// LastRefresh returns the time of the last successful refresh, which is zero if never
+	// refreshed successfully, and the total number of failed refreshes
+	LastRefresh() (lastSuccess time.Time, failures uint64)
+}
+
+// CachingTeamLister serves teams from a cache which is refreshed in the background, so
+// that scrapes never trigger the underlying API call per team. If a refresh fails, the
+// previously cached teams continue to be served.
+type CachingTeamLister struct {
+	TeamLister      TeamLister
+	RefreshInterval time.Duration
+
+	lock        *sync.RWMutex
+	teams       []*Team
+	lastRefresh time.Time
+	failures    uint64
+	logger      *zap.SugaredLogger
+}
+
+func NewCachingTeamLister(logger *zap.SugaredLogger,
+	teamLister TeamLister,
+	refreshInterval time.Duration) *CachingTeamLister {
+	logger = getComponentLogger(logger, "caching-team-lister")
+
+	return &CachingTeamLister{
+		TeamLister:      teamLister,
+		RefreshInterval: refreshInterval,
+		lock:            new(sync.RWMutex),
+		logger:          logger,
+	}
+}
+
+// Run refreshes the cache immediately, and then every refresh interval until the
+// context is cancelled
+func (l *CachingTeamLister) Run(ctx context.Context) {
+	ticker := time.NewTicker(l.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		l.refresh()
+
+		select {
+		case <-ctx.Done():
+			l.logger.Infow("stopping cache refresh")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// List returns the cached teams, which are empty until the cache is first populated.
+// Not yet being populated is not an error, as it is expected while the exporter starts.
+func (l *CachingTeamLister) List() ([]*Team, error) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	if l.lastRefresh.IsZero() {
+		l.logger.Infow("team cache not yet populated")
+		return []*Team{}, nil
+	}
+
+	return l.teams, nil
+}
+
+// SetTeamLister replaces the underlying team lister, e.g. when the credentials change
+// on reload. The cached teams continue to be served until the next refresh.
+func (l *CachingTeamLister) SetTeamLister(teamLister TeamLister) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.TeamLister = teamLister
+}
+
+func (l *CachingTeamLister) LastRefresh() (time.Time, uint64) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return l.lastRefresh, l.failures
+}
+
+func (l *CachingTeamLister) refresh() {
+	l.lock.RLock()
+	teamLister := l.TeamLister
+	l.lock.RUnlock()
+
+	teams, err := teamLister.List()
+	if err != nil {
+		l.lock.Lock()
+		l.failures++
+		l.lock.Unlock()
+
+		l.logger.Errorw("refreshing team cache", "error", err)
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.teams = teams
+	l.lastRefresh = time.Now()
+
+	l.logger.Infow("refreshed team cache", "count", len(teams))
+}
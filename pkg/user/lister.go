@@ -0,0 +1,76 @@
+package user
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	jsonhttp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/jsonhttp"
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp/user"
+	"go.uber.org/zap"
+)
+
+type Lister interface {
+	List() ([]*User, error)
+}
+
+// APILister lists all users of the account
+type APILister struct {
+	logger       *zap.SugaredLogger
+	unmarshaller *jsonhttp.PaginatedJSONHTTPUnmarshaller[*apiresp.ListUsersResp, apiresp.ListUsersRespDataItem]
+}
+
+func NewAPILister(logger *zap.SugaredLogger,
+	APIKey, APISecret, APIURL string,
+	timeout time.Duration) (*APILister, error) {
+	logger = getComponentLogger(logger, "api-lister")
+
+	url, err := url.Parse(APIURL + "/v1/users")
+	if err != nil {
+		logger.Errorw("parsing API URL", "url", APIURL, "error", err)
+		return nil, fmt.Errorf("parsing API URL %q: %w", APIURL, err)
+	}
+
+	apiToken := base64.StdEncoding.EncodeToString([]byte(APIKey + ":" + APISecret))
+	httpClient := &http.Client{
+		Timeout: timeout,
+	}
+
+	unmarshaller := jsonhttp.NewPaginatedJSONHTTPUnmarshaller[*apiresp.ListUsersResp, apiresp.ListUsersRespDataItem](logger,
+		url,
+		apiToken,
+		httpClient)
+
+	return &APILister{
+		logger:       logger,
+		unmarshaller: unmarshaller,
+	}, nil
+}
+
+func (l *APILister) List() ([]*User, error) {
+	items, err := l.unmarshaller.UnmarshallAllPagesFromHTTPGet()
+	if err != nil {
+		l.logger.Errorw("getting JSON HTTP responses", "error", err)
+		return nil, fmt.Errorf("getting JSON HTTP responses: %w", err)
+	}
+
+	users := make([]*User, 0, len(items))
+	for _, item := range items {
+		user := &User{
+			ID:        item.ID,
+			Email:     item.Email,
+			Role:      item.Role,
+			Active:    item.Active,
+			Verified:  item.Verified,
+			LastLogin: item.LoggedInAt,
+		}
+
+		l.logger.Infow("discovered user", "id", user.ID, "role", user.Role)
+		users = append(users, user)
+	}
+
+	l.logger.Infow("listed users from API", "count", len(users))
+	return users, nil
+}
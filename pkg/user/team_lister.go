@@ -0,0 +1,122 @@
+package user
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	jsonhttp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/jsonhttp"
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp/user"
+	"go.uber.org/zap"
+)
+
+type TeamLister interface {
+	List() ([]*Team, error)
+}
+
+// APITeamLister lists all teams of the account, along with the groups each team
+// is a member of. This requires one API call per team, so it should not be called on
+// every scrape (see CachingTeamLister).
+type APITeamLister struct {
+	APIURL string
+
+	apiToken     string
+	httpClient   *http.Client
+	logger       *zap.SugaredLogger
+	unmarshaller *jsonhttp.PaginatedJSONHTTPUnmarshaller[*apiresp.ListTeamsResp, apiresp.ListTeamsRespDataItem]
+}
+
+func NewAPITeamLister(logger *zap.SugaredLogger,
+	APIKey, APISecret, APIURL string,
+	timeout time.Duration) (*APITeamLister, error) {
+	logger = getComponentLogger(logger, "api-team-lister")
+
+	url, err := url.Parse(APIURL + "/v1/teams")
+	if err != nil {
+		logger.Errorw("parsing API URL", "url", APIURL, "error", err)
+		return nil, fmt.Errorf("parsing API URL %q: %w", APIURL, err)
+	}
+
+	apiToken := base64.StdEncoding.EncodeToString([]byte(APIKey + ":" + APISecret))
+	httpClient := &http.Client{
+		Timeout: timeout,
+	}
+
+	unmarshaller := jsonhttp.NewPaginatedJSONHTTPUnmarshaller[*apiresp.ListTeamsResp, apiresp.ListTeamsRespDataItem](logger,
+		url,
+		apiToken,
+		httpClient)
+
+	return &APITeamLister{
+		APIURL:       APIURL,
+		apiToken:     apiToken,
+		httpClient:   httpClient,
+		logger:       logger,
+		unmarshaller: unmarshaller,
+	}, nil
+}
+
+func (l *APITeamLister) List() ([]*Team, error) {
+	items, err := l.unmarshaller.UnmarshallAllPagesFromHTTPGet()
+	if err != nil {
+		l.logger.Errorw("getting JSON HTTP responses", "error", err)
+		return nil, fmt.Errorf("getting JSON HTTP responses: %w", err)
+	}
+
+	teams := make([]*Team, 0, len(items))
+	for _, item := range items {
+		groupMemberships, err := l.listGroupMemberships(item.ID)
+		if err != nil {
+			l.logger.Errorw("listing team group memberships", "id", item.ID, "name", item.Name, "error", err)
+			return nil, fmt.Errorf("listing group memberships of team %q: %w", item.Name, err)
+		}
+
+		team := &Team{
+			ID:               item.ID,
+			Name:             item.Name,
+			Role:             item.Role,
+			GroupMemberships: groupMemberships,
+		}
+
+		l.logger.Infow("discovered team",
+			"id", team.ID,
+			"name", team.Name,
+			"group_membership_count", len(team.GroupMemberships))
+		teams = append(teams, team)
+	}
+
+	l.logger.Infow("listed teams from API", "count", len(teams))
+	return teams, nil
+}
+
+func (l *APITeamLister) listGroupMemberships(teamID string) ([]*GroupMembership, error) {
+	// The URL differs per team, so an unmarshaller is constructed for each team
+	url, err := url.Parse(fmt.Sprintf("%s/v1/teams/%s/groups", l.APIURL, url.PathEscape(teamID)))
+	if err != nil {
+		l.logger.Errorw("parsing API URL", "url", l.APIURL, "team_id", teamID, "error", err)
+		return nil, fmt.Errorf("parsing API URL %q: %w", l.APIURL, err)
+	}
+
+	unmarshaller := jsonhttp.NewPaginatedJSONHTTPUnmarshaller[*apiresp.ListTeamMembershipsResp, apiresp.ListTeamMembershipsRespDataItem](l.logger,
+		url,
+		l.apiToken,
+		l.httpClient)
+
+	items, err := unmarshaller.UnmarshallAllPagesFromHTTPGet()
+	if err != nil {
+		l.logger.Errorw("getting JSON HTTP responses", "team_id", teamID, "error", err)
+		return nil, fmt.Errorf("getting JSON HTTP responses: %w", err)
+	}
+
+	groupMemberships := make([]*GroupMembership, 0, len(items))
+	for _, item := range items {
+		groupMemberships = append(groupMemberships, &GroupMembership{
+			GroupID: item.ID,
+			Role:    item.Role,
+		})
+	}
+
+	return groupMemberships, nil
+}
@@ -0,0 +1,24 @@
+package user
+
+import "time"
+
+type User struct {
+	ID        string
+	Email     string
+	Role      string
+	Active    bool
+	Verified  bool
+	LastLogin *time.Time // Nil if the user has never logged in
+}
+
+type Team struct {
+	ID               string
+	Name             string
+	Role             string
+	GroupMemberships []*GroupMembership
+}
+
+type GroupMembership struct {
+	GroupID string
+	Role    string
+}
@@ -0,0 +1,40 @@
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version and Revision are set when building the exporter, e.g.
+//
+//	go build -ldflags "\
+//	  -X github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/version.Version=v1.2.3 \
+//	  -X github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/version.Revision=$(git rev-parse HEAD)" \
+//	  ./cmd
+//
+// If the revision is not set, the VCS revision recorded by the Go toolchain is used,
+// if any.
+var (
+	Version  = "unknown"
+	Revision = "unknown"
+)
+
+// GoVersion is the version of Go with which the exporter was built
+var GoVersion = runtime.Version()
+
+func init() {
+	if Revision != "unknown" {
+		return
+	}
+
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+
+	for _, setting := range buildInfo.Settings {
+		if setting.Key == "vcs.revision" {
+			Revision = setting.Value
+		}
+	}
+}
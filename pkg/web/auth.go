@@ -0,0 +1,205 @@
+package web
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+const bearerPrefix = "Bearer "
+
+/*
+AuthConfig is the schema of the web config file, in the style of the Prometheus
+exporter-toolkit. Requests must authenticate as any one of the basic auth users, or
+with any one of the bearer tokens. Unknown fields are rejected.
+
+	basic_auth_users:
+	  <username>: <bcrypt hash of password>
+	bearer_tokens:
+	  - <token>
+*/
+type AuthConfig struct {
+	BasicAuthUsers map[string]string `yaml:"basic_auth_users"`
+	BearerTokens   []string          `yaml:"bearer_tokens"`
+}
+
+func LoadAuthConfigFile(logger *zap.SugaredLogger, filename string) (*AuthConfig, error) {
+	logger = getComponentLogger(logger, "auth-config-loader")
+
+	file, err := os.Open(filename)
+	if err != nil {
+		logger.Errorw("opening web config file", "filename", filename, "error", err)
+		return nil, fmt.Errorf("opening web config file %q: %w", filename, err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+
+	config := new(AuthConfig)
+	if err := decoder.Decode(config); err != nil {
+		logger.Errorw("decoding web config file", "filename", filename, "error", err)
+		return nil, fmt.Errorf("decoding web config file %q: %w", filename, err)
+	}
+
+	if err := config.validate(); err != nil {
+		logger.Errorw("validating web config file", "filename", filename, "error", err)
+		return nil, fmt.Errorf("validating web config file %q: %w", filename, err)
+	}
+
+	logger.Infow("loaded web config file",
+		"filename", filename,
+		"basic_auth_users", len(config.BasicAuthUsers),
+		"bearer_tokens", len(config.BearerTokens))
+	return config, nil
+}
+
+func (c *AuthConfig) validate() error {
+	if len(c.BasicAuthUsers) == 0 && len(c.BearerTokens) == 0 {
+		return errors.New("no basic auth users or bearer tokens")
+	}
+
+	for username, hash := range c.BasicAuthUsers {
+		if username == "" || strings.Contains(username, ":") {
+			return fmt.Errorf("invalid basic auth username %q", username)
+		}
+
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return fmt.Errorf("invalid bcrypt hash of basic auth user %q: %w", username, err)
+		}
+	}
+
+	for i, token := range c.BearerTokens {
+		if token == "" {
+			return fmt.Errorf("empty bearer token at index %d", i)
+		}
+	}
+
+	return nil
+}
+
+// AuthHandler authenticates requests before passing them on to the handler. Requests
+// for exempt paths, which are authenticated by other means, are passed on as-is.
+type AuthHandler struct {
+	Handler     http.Handler
+	ExemptPaths []string
+
+	lock   *sync.RWMutex
+	config *AuthConfig
+	// Verifying a bcrypt hash is deliberately slow, so the credentials which have been
+	// verified are cached, keyed by a hash of the username, password hash and password
+	verifiedCredentials map[[sha256.Size]byte]struct{}
+	dummyHash           []byte
+	logger              *zap.SugaredLogger
+}
+
+func NewAuthHandler(logger *zap.SugaredLogger,
+	config *AuthConfig,
+	handler http.Handler,
+	exemptPaths ...string) (*AuthHandler, error) {
+	logger = getComponentLogger(logger, "auth-handler")
+
+	// The password of unknown users is compared against a dummy hash, so that unknown
+	// users cannot be distinguished from known users by the response time
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte("dummy"), bcrypt.DefaultCost)
+	if err != nil {
+		logger.Errorw("generating dummy bcrypt hash", "error", err)
+		return nil, fmt.Errorf("generating dummy bcrypt hash: %w", err)
+	}
+
+	return &AuthHandler{
+		Handler:             handler,
+		ExemptPaths:         exemptPaths,
+		lock:                new(sync.RWMutex),
+		config:              config,
+		verifiedCredentials: make(map[[sha256.Size]byte]struct{}),
+		dummyHash:           dummyHash,
+		logger:              logger,
+	}, nil
+}
+
+// SetConfig atomically replaces the config, e.g. when the config is reloaded
+func (h *AuthHandler) SetConfig(config *AuthConfig) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.config = config
+}
+
+func (h *AuthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, path := range h.ExemptPaths {
+		if r.URL.Path == path {
+			h.Handler.ServeHTTP(w, r)
+			return
+		}
+	}
+
+	if !h.authenticate(r) {
+		h.logger.Errorw("unauthenticated request", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+		w.Header().Set("WWW-Authenticate", `Basic realm="fivetran-exporter"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	h.Handler.ServeHTTP(w, r)
+}
+
+func (h *AuthHandler) authenticate(r *http.Request) bool {
+	h.lock.RLock()
+	config := h.config
+	h.lock.RUnlock()
+
+	authorization := r.Header.Get("Authorization")
+	if strings.HasPrefix(authorization, bearerPrefix) {
+		token := []byte(strings.TrimPrefix(authorization, bearerPrefix))
+
+		// Compare against every token in constant time, so the tokens cannot be
+		// guessed from response timings
+		authenticated := false
+		for _, bearerToken := range config.BearerTokens {
+			if subtle.ConstantTimeCompare(token, []byte(bearerToken)) == 1 {
+				authenticated = true
+			}
+		}
+
+		return authenticated
+	}
+
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+
+	hash, known := config.BasicAuthUsers[username]
+	if !known {
+		_ = bcrypt.CompareHashAndPassword(h.dummyHash, []byte(password))
+		return false
+	}
+
+	cacheKey := sha256.Sum256([]byte(username + ":" + hash + ":" + password))
+	h.lock.RLock()
+	_, verified := h.verifiedCredentials[cacheKey]
+	h.lock.RUnlock()
+	if verified {
+		return true
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return false
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.verifiedCredentials[cacheKey] = struct{}{}
+
+	return true
+}
@@ -0,0 +1,143 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Listen addresses with this prefix are the paths of Unix sockets
+const unixAddressPrefix = "unix:"
+
+// Scrapes fan out to the Fivetran API, so the write timeout must allow for a number
+// of sequential API calls
+const (
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = 30 * time.Second
+	writeTimeout      = 2 * time.Minute
+	idleTimeout       = 2 * time.Minute
+)
+
+// Server serves a handler on one or more listen addresses, optionally over TLS.
+// Addresses are TCP host:port addresses (e.g. ":9799" or "[::1]:9799"), or Unix
+// socket paths prefixed with "unix:".
+type Server struct {
+	Addresses   []string
+	TLSCertFile string // Optional, empty if TLS is disabled
+	TLSKeyFile  string // Optional, empty if TLS is disabled
+
+	server *http.Server
+	logger *zap.SugaredLogger
+}
+
+func NewServer(logger *zap.SugaredLogger,
+	handler http.Handler,
+	addresses []string,
+	tlsCertFile, tlsKeyFile string) *Server {
+	logger = getComponentLogger(logger, "server")
+
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+
+	return &Server{
+		Addresses:   addresses,
+		TLSCertFile: tlsCertFile,
+		TLSKeyFile:  tlsKeyFile,
+		server:      server,
+		logger:      logger,
+	}
+}
+
+// ListenAndServe listens on all of the addresses before serving on any of them, so
+// that an unusable address is reported immediately. It blocks until serving on any
+// of the addresses fails, returning the error, or until the server is shut down,
+// returning http.ErrServerClosed.
+func (s *Server) ListenAndServe() error {
+	listeners := make([]net.Listener, 0, len(s.Addresses))
+	for _, address := range s.Addresses {
+		listener, err := s.listen(address)
+		if err != nil {
+			for _, listener := range listeners {
+				listener.Close()
+			}
+
+			s.logger.Errorw("listening", "address", address, "error", err)
+			return fmt.Errorf("listening on %q: %w", address, err)
+		}
+		listeners = append(listeners, listener)
+	}
+
+	errs := make(chan error, len(listeners))
+	for _, listener := range listeners {
+		go func(listener net.Listener) {
+			errs <- s.serve(listener)
+		}(listener)
+	}
+
+	return <-errs
+}
+
+// Shutdown stops listening on all of the addresses, and then waits for in-flight
+// requests to complete until the context is cancelled
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.logger.Infow("shutting down", "addresses", s.Addresses)
+
+	if err := s.server.Shutdown(ctx); err != nil {
+		s.logger.Errorw("shutting down", "addresses", s.Addresses, "error", err)
+		return fmt.Errorf("shutting down: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) listen(address string) (net.Listener, error) {
+	if !strings.HasPrefix(address, unixAddressPrefix) {
+		return net.Listen("tcp", address)
+	}
+
+	path := strings.TrimPrefix(address, unixAddressPrefix)
+
+	// A socket left behind by a previous run would prevent listening
+	info, err := os.Stat(path)
+	if err == nil && info.Mode()&fs.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("removing stale socket %q: %w", path, err)
+		}
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("checking for stale socket %q: %w", path, err)
+	}
+
+	return net.Listen("unix", path)
+}
+
+func (s *Server) serve(listener net.Listener) error {
+	s.logger.Infow("serving", "address", listener.Addr(), "tls", s.TLSCertFile != "")
+
+	var err error
+	if s.TLSCertFile != "" {
+		err = s.server.ServeTLS(listener, s.TLSCertFile, s.TLSKeyFile)
+	} else {
+		err = s.server.Serve(listener)
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		s.logger.Infow("stopped serving", "address", listener.Addr())
+		return err
+	}
+
+	s.logger.Errorw("serving", "address", listener.Addr(), "error", err)
+	return fmt.Errorf("serving on %q: %w", listener.Addr(), err)
+}
@@ -0,0 +1,30 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventTypeSyncStart EventType = "sync_start"
+	EventTypeSyncEnd   EventType = "sync_end"
+)
+
+// Event is a webhook event delivered by Fivetran.
+// Only the fields relevant to the exporter are decoded.
+type Event struct {
+	Event              EventType
+	Created            time.Time
+	ConnectorType      string `json:"connector_type"`
+	ConnectorID        string `json:"connector_id"`
+	DestinationGroupID string `json:"destination_group_id"`
+	Data               json.RawMessage
+}
+
+// SyncEndData is the event data of a sync_end event
+type SyncEndData struct {
+	Status string // e.g. SUCCESSFUL, FAILURE, FAILURE_WITH_TASK, RESCHEDULED
+	Reason string
+}
@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	jsonhttp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/jsonhttp"
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp/webhook"
+	"go.uber.org/zap"
+)
+
+type Lister interface {
+	List() ([]*Webhook, error)
+}
+
+// APILister lists all webhooks visible to the API key
+type APILister struct {
+	logger       *zap.SugaredLogger
+	unmarshaller *jsonhttp.PaginatedJSONHTTPUnmarshaller[*apiresp.ListWebhooksResp, apiresp.Webhook]
+}
+
+func NewAPILister(logger *zap.SugaredLogger,
+	APIKey, APISecret, APIURL string,
+	timeout time.Duration) (*APILister, error) {
+	logger = getComponentLogger(logger, "api-lister")
+
+	url, err := url.Parse(APIURL + "/v1/webhooks")
+	if err != nil {
+		logger.Errorw("parsing API URL", "url", APIURL, "error", err)
+		return nil, fmt.Errorf("parsing API URL %q: %w", APIURL, err)
+	}
+
+	apiToken := base64.StdEncoding.EncodeToString([]byte(APIKey + ":" + APISecret))
+	httpClient := &http.Client{
+		Timeout: timeout,
+	}
+
+	unmarshaller := jsonhttp.NewPaginatedJSONHTTPUnmarshaller[*apiresp.ListWebhooksResp, apiresp.Webhook](logger,
+		url,
+		apiToken,
+		httpClient)
+
+	return &APILister{
+		logger:       logger,
+		unmarshaller: unmarshaller,
+	}, nil
+}
+
+func (l *APILister) List() ([]*Webhook, error) {
+	items, err := l.unmarshaller.UnmarshallAllPagesFromHTTPGet()
+	if err != nil {
+		l.logger.Errorw("getting JSON HTTP responses", "error", err)
+		return nil, fmt.Errorf("getting JSON HTTP responses: %w", err)
+	}
+
+	webhooks := make([]*Webhook, 0, len(items))
+	for _, item := range items {
+		webhook := convertWebhook(item)
+
+		l.logger.Infow("discovered webhook",
+			"id", webhook.ID,
+			"group_id", webhook.GroupID,
+			"url", webhook.URL)
+		webhooks = append(webhooks, webhook)
+	}
+
+	l.logger.Infow("listed webhooks from API", "count", len(webhooks))
+	return webhooks, nil
+}
+
+func convertWebhook(apiWebhook apiresp.Webhook) *Webhook {
+	return &Webhook{
+		ID:      apiWebhook.ID,
+		GroupID: apiWebhook.GroupID,
+		URL:     apiWebhook.URL,
+		Events:  apiWebhook.Events,
+		Active:  apiWebhook.Active,
+	}
+}
@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+const (
+	SignatureHeader = "X-Fivetran-Signature-256"
+
+	maxBodyBytes = 1 << 20
+)
+
+type EventHandler interface {
+	HandleEvent(event *Event) error
+}
+
+// Receiver accepts Fivetran webhook deliveries, verifies their signature,
+// and passes the events on to the event handler
+type Receiver struct {
+	EventHandler EventHandler
+
+	secret []byte
+	logger *zap.SugaredLogger
+}
+
+func NewReceiver(logger *zap.SugaredLogger, secret string, eventHandler EventHandler) *Receiver {
+	logger = getComponentLogger(logger, "receiver")
+
+	return &Receiver{
+		EventHandler: eventHandler,
+		secret:       []byte(secret),
+		logger:       logger,
+	}
+}
+
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body := new(bytes.Buffer)
+	if _, err := io.Copy(body, http.MaxBytesReader(w, req.Body, maxBodyBytes)); err != nil {
+		r.logger.Errorw("reading webhook body", "error", err)
+		http.Error(w, "error reading body", http.StatusBadRequest)
+		return
+	}
+	bodyBytes := body.Bytes()
+
+	if err := r.verifySignature(req.Header.Get(SignatureHeader), bodyBytes); err != nil {
+		r.logger.Errorw("verifying webhook signature", "remote_addr", req.RemoteAddr, "error", err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	event := new(Event)
+	if err := json.Unmarshal(bodyBytes, event); err != nil {
+		r.logger.Errorw("unmarshalling webhook event", "error", err)
+		http.Error(w, "invalid event", http.StatusBadRequest)
+		return
+	}
+
+	r.logger.Infow("received webhook event",
+		"event", event.Event,
+		"connector_id", event.ConnectorID,
+		"created", event.Created)
+
+	if err := r.EventHandler.HandleEvent(event); err != nil {
+		// The event was delivered correctly, so do not ask Fivetran to retry
+		// by returning an error status, as the retry would be handled the same way
+		r.logger.Errorw("handling webhook event",
+			"event", event.Event,
+			"connector_id", event.ConnectorID,
+			"error", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *Receiver) verifySignature(signature string, body []byte) error {
+	if signature == "" {
+		return fmt.Errorf("signature header %q not set", SignatureHeader)
+	}
+
+	// Fivetran sends the signature as upper-case hex, but decoding is case-insensitive
+	signatureBytes, err := hex.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("decoding signature: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, r.secret)
+	mac.Write(body)
+	if !hmac.Equal(signatureBytes, mac.Sum(nil)) {
+		return fmt.Errorf("signature mismatch")
+	}
+
+	return nil
+}
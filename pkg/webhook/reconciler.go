@@ -0,0 +1,193 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/group"
+	"go.uber.org/zap"
+)
+
+// Events are the webhook events required by the receiver
+var Events = []string{string(EventTypeSyncStart), string(EventTypeSyncEnd)}
+
+type RegistrationReporter interface {
+	Registered() map[string]bool
+}
+
+// Reconciler ensures that each group has an active webhook delivering the required
+// events to the receiver URL. The API does not return webhook secrets, so the secret is
+// set on each existing webhook the first time it is reconciled by this process.
+type Reconciler struct {
+	Groups            []*group.Group
+	Spec              *Spec
+	Lister            Lister
+	Registrar         Registrar
+	ReconcileInterval time.Duration
+
+	lock          *sync.RWMutex
+	registered    map[string]bool // Keyed by group name
+	secretUpdated map[string]bool // Keyed by webhook ID
+	logger        *zap.SugaredLogger
+}
+
+func NewReconciler(logger *zap.SugaredLogger,
+	groups []*group.Group,
+	spec *Spec,
+	lister Lister,
+	registrar Registrar,
+	reconcileInterval time.Duration) *Reconciler {
+	logger = getComponentLogger(logger, "reconciler")
+
+	registered := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		registered[group.Name] = false
+	}
+
+	return &Reconciler{
+		Groups:            groups,
+		Spec:              spec,
+		Lister:            lister,
+		Registrar:         registrar,
+		ReconcileInterval: reconcileInterval,
+		lock:              new(sync.RWMutex),
+		registered:        registered,
+		secretUpdated:     make(map[string]bool),
+		logger:            logger,
+	}
+}
+
+// Run reconciles immediately, and then every reconcile interval until the
+// context is cancelled
+func (r *Reconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.ReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		r.reconcile()
+
+		select {
+		case <-ctx.Done():
+			r.logger.Infow("stopping webhook reconciliation")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SetSources atomically replaces the groups, lister and registrar, e.g. when the config
+// is reloaded. The webhooks whose secret has been set by this process are remembered,
+// so that the secrets are not set again.
+func (r *Reconciler) SetSources(groups []*group.Group, lister Lister, registrar Registrar) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	// Groups which are no longer reconciled are no longer reported
+	registered := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		registered[group.Name] = r.registered[group.Name]
+	}
+
+	r.Groups = groups
+	r.Lister = lister
+	r.Registrar = registrar
+	r.registered = registered
+}
+
+// Registered returns whether the webhook is known to be registered, keyed by group name
+func (r *Reconciler) Registered() map[string]bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	registered := make(map[string]bool, len(r.registered))
+	for groupName, isRegistered := range r.registered {
+		registered[groupName] = isRegistered
+	}
+
+	return registered
+}
+
+func (r *Reconciler) reconcile() {
+	r.lock.RLock()
+	groups := r.Groups
+	lister := r.Lister
+	registrar := r.Registrar
+	r.lock.RUnlock()
+
+	webhooks, err := lister.List()
+	if err != nil {
+		// Leave the registration state as it was, as we do not know any better
+		r.logger.Errorw("listing webhooks", "error", err)
+		return
+	}
+
+	for _, group := range groups {
+		err := r.reconcileGroup(group, webhooks, registrar)
+		if err != nil {
+			r.logger.Errorw("reconciling group webhook",
+				"group_id", group.ID,
+				"group_name", group.Name,
+				"error", err)
+		}
+
+		r.lock.Lock()
+		// The group may have been removed by a reload during the reconciliation
+		if _, ok := r.registered[group.Name]; ok {
+			r.registered[group.Name] = err == nil
+		}
+		r.lock.Unlock()
+	}
+}
+
+func (r *Reconciler) reconcileGroup(group *group.Group, webhooks []*Webhook, registrar Registrar) error {
+	for _, webhook := range webhooks {
+		if webhook.GroupID != group.ID || webhook.URL != r.Spec.URL {
+			continue
+		}
+
+		if webhook.Active && sameEvents(webhook.Events, r.Spec.Events) && r.secretUpdated[webhook.ID] {
+			r.logger.Infow("group webhook up to date",
+				"id", webhook.ID,
+				"group_id", group.ID,
+				"group_name", group.Name)
+			return nil
+		}
+
+		if _, err := registrar.UpdateWebhook(webhook.ID, r.Spec); err != nil {
+			return fmt.Errorf("updating webhook %q: %w", webhook.ID, err)
+		}
+		r.secretUpdated[webhook.ID] = true
+
+		return nil
+	}
+
+	// If we get here, there was no webhook for the group delivering to the receiver URL
+	webhook, err := registrar.CreateGroupWebhook(group.ID, r.Spec)
+	if err != nil {
+		return fmt.Errorf("creating webhook: %w", err)
+	}
+	r.secretUpdated[webhook.ID] = true
+
+	return nil
+}
+
+func sameEvents(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	sortedA := append([]string(nil), a...)
+	sortedB := append([]string(nil), b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	for i := range sortedA {
+		if sortedA[i] != sortedB[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	jsonhttp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/jsonhttp"
+	apireq "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/req/webhook"
+	apiresp "github.com/jhwbarlow/prometheus-fivetran-exporter/pkg/api/resp/webhook"
+	"go.uber.org/zap"
+)
+
+// Spec is the desired configuration of a webhook
+type Spec struct {
+	URL    string
+	Events []string
+	Secret string
+}
+
+// Equal returns whether the specs are the same, regardless of the order of the events
+func (s *Spec) Equal(other *Spec) bool {
+	return s.URL == other.URL && s.Secret == other.Secret && sameEvents(s.Events, other.Events)
+}
+
+type Registrar interface {
+	CreateGroupWebhook(groupID string, spec *Spec) (*Webhook, error)
+	UpdateWebhook(id string, spec *Spec) (*Webhook, error)
+}
+
+type APIRegistrar struct {
+	APIURL string
+
+	apiToken   string
+	httpClient *http.Client
+	logger     *zap.SugaredLogger
+}
+
+func NewAPIRegistrar(logger *zap.SugaredLogger,
+	APIKey, APISecret, APIURL string,
+	timeout time.Duration) (*APIRegistrar, error) {
+	logger = getComponentLogger(logger, "api-registrar")
+
+	if _, err := url.Parse(APIURL); err != nil {
+		logger.Errorw("parsing API URL", "url", APIURL, "error", err)
+		return nil, fmt.Errorf("parsing API URL %q: %w", APIURL, err)
+	}
+
+	apiToken := base64.StdEncoding.EncodeToString([]byte(APIKey + ":" + APISecret))
+	httpClient := &http.Client{
+		Timeout: timeout,
+	}
+
+	return &APIRegistrar{
+		APIURL:     APIURL,
+		apiToken:   apiToken,
+		httpClient: httpClient,
+		logger:     logger,
+	}, nil
+}
+
+func (r *APIRegistrar) CreateGroupWebhook(groupID string, spec *Spec) (*Webhook, error) {
+	unmarshaller, err := r.newUnmarshaller(fmt.Sprintf("/v1/webhooks/group/%s", url.PathEscape(groupID)))
+	if err != nil {
+		return nil, err
+	}
+
+	webhookResp, err := unmarshaller.UnmarshallJSONFromHTTPPost(newWebhookReq(spec))
+	if err != nil {
+		r.logger.Errorw("getting JSON HTTP response", "group_id", groupID, "error", err)
+		return nil, fmt.Errorf("getting JSON HTTP response: %w", err)
+	}
+
+	webhook := convertWebhook(webhookResp.Data)
+	r.logger.Infow("created group webhook", "id", webhook.ID, "group_id", groupID, "url", webhook.URL)
+	return webhook, nil
+}
+
+func (r *APIRegistrar) UpdateWebhook(id string, spec *Spec) (*Webhook, error) {
+	unmarshaller, err := r.newUnmarshaller(fmt.Sprintf("/v1/webhooks/%s", url.PathEscape(id)))
+	if err != nil {
+		return nil, err
+	}
+
+	webhookResp, err := unmarshaller.UnmarshallJSONFromHTTPPatch(newWebhookReq(spec))
+	if err != nil {
+		r.logger.Errorw("getting JSON HTTP response", "id", id, "error", err)
+		return nil, fmt.Errorf("getting JSON HTTP response: %w", err)
+	}
+
+	webhook := convertWebhook(webhookResp.Data)
+	r.logger.Infow("updated webhook", "id", webhook.ID, "group_id", webhook.GroupID, "url", webhook.URL)
+	return webhook, nil
+}
+
+func (r *APIRegistrar) newUnmarshaller(path string) (*jsonhttp.JSONHTTPUnmarshaller[*apiresp.WebhookResp], error) {
+	url, err := url.Parse(r.APIURL + path)
+	if err != nil {
+		r.logger.Errorw("parsing API URL", "url", r.APIURL, "path", path, "error", err)
+		return nil, fmt.Errorf("parsing API URL %q: %w", r.APIURL, err)
+	}
+
+	return jsonhttp.NewJSONHTTPUnmarshaller[*apiresp.WebhookResp](r.logger,
+		url,
+		r.apiToken,
+		r.httpClient), nil
+}
+
+func newWebhookReq(spec *Spec) *apireq.WebhookReq {
+	return &apireq.WebhookReq{
+		URL:    spec.URL,
+		Events: spec.Events,
+		Active: true,
+		Secret: spec.Secret,
+	}
+}
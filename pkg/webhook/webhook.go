@@ -0,0 +1,10 @@
+package webhook
+
+// Webhook is a webhook configured in Fivetran, delivering events to a URL
+type Webhook struct {
+	ID      string
+	GroupID string // Empty for account webhooks
+	URL     string
+	Events  []string
+	Active  bool
+}